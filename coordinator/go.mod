@@ -12,6 +12,7 @@ require (
 	github.com/stretchr/testify v1.9.0
 	github.com/urfave/cli/v2 v2.25.7
 	golang.org/x/arch v0.5.0 // indirect
+	golang.org/x/time v0.3.0
 	gorm.io/gorm v1.25.7-0.20240204074919-46816ad31dde
 )
 
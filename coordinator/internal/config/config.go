@@ -2,6 +2,9 @@ package config
 
 import (
 	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
 	"os"
 	"path/filepath"
 
@@ -25,6 +28,24 @@ type ProverManager struct {
 	MaxVerifierWorkers int `json:"max_verifier_workers"`
 	// MinProverVersion is the minimum version of the prover that is required.
 	MinProverVersion string `json:"min_prover_version"`
+	// MinBlockNumber and MaxBlockNumber limit the chunk/batch tasks assigned by this coordinator to the ones whose
+	// blocks are all within [MinBlockNumber, MaxBlockNumber]. 0 means no limit.
+	MinBlockNumber uint64 `json:"min_block_number"`
+	MaxBlockNumber uint64 `json:"max_block_number"`
+	// MinPlausibleProofTimeSec flags the proofs submitted faster than this duration (in seconds) as suspicious,
+	// e.g. pre-computed proofs. 0 disables the check.
+	MinPlausibleProofTimeSec int `json:"min_plausible_proof_time_sec"`
+	// VerifyOnly verifies the submitted proofs and records the verification result in prover_task,
+	// but doesn't store the proofs or mark the chunk/batch as verified. A chunk/batch is proved at most
+	// session_attempts times and then marked as failed, so the provers move on to the next ones.
+	VerifyOnly bool `json:"verify_only"`
+	// MaxAssignmentsPerSecond limits how many chunk/batch tasks each coordinator_api instance assigns to
+	// provers per second, shared by all task types. With several replicas the overall rate is this limit
+	// times the number of replicas. 0 means no limit.
+	MaxAssignmentsPerSecond float64 `json:"max_assignments_per_second"`
+	// OrmQueryTimeoutMs bounds each database query or transaction of the cron checkers and of the get_task
+	// and submit_proof handlers (in milliseconds), so a slow database can't stall them. 0 means no timeout.
+	OrmQueryTimeoutMs int `json:"orm_query_timeout_ms"`
 }
 
 // L2 loads l2geth configuration items.
@@ -69,5 +90,88 @@ func NewConfig(file string) (*Config, error) {
 		return nil, err
 	}
 
+	if err = cfg.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid config file %s: %w", file, err)
+	}
+
 	return cfg, nil
 }
+
+// Validate checks that all required sections are present and that the values are sane.
+func (c *Config) Validate() error {
+	if c.ProverManager == nil {
+		return errors.New("prover_manager config is missing")
+	}
+	if err := c.ProverManager.Validate(); err != nil {
+		return fmt.Errorf("prover_manager: %w", err)
+	}
+	if c.DB == nil {
+		return errors.New("db config is missing")
+	}
+	if c.L2 == nil {
+		return errors.New("l2 config is missing")
+	}
+	if c.Auth == nil {
+		return errors.New("auth config is missing")
+	}
+	if err := c.Auth.Validate(); err != nil {
+		return fmt.Errorf("auth: %w", err)
+	}
+	return nil
+}
+
+// Validate checks the prover manager limits and the verifier config.
+func (p *ProverManager) Validate() error {
+	if p.ProversPerSession == 0 {
+		return errors.New("provers_per_session must be positive")
+	}
+	if p.SessionAttempts == 0 {
+		return errors.New("session_attempts must be positive")
+	}
+	if p.BatchCollectionTimeSec <= 0 {
+		return fmt.Errorf("batch_collection_time_sec must be positive, got %d", p.BatchCollectionTimeSec)
+	}
+	if p.ChunkCollectionTimeSec <= 0 {
+		return fmt.Errorf("chunk_collection_time_sec must be positive, got %d", p.ChunkCollectionTimeSec)
+	}
+	// the exclusive upper bound max_block_number+1 must stay within the block number range of the database
+	if p.MaxBlockNumber >= math.MaxInt64 {
+		return fmt.Errorf("max_block_number %d must be less than %d", p.MaxBlockNumber, uint64(math.MaxInt64))
+	}
+	if p.MaxBlockNumber != 0 && p.MaxBlockNumber < p.MinBlockNumber {
+		return fmt.Errorf("max_block_number %d is less than min_block_number %d", p.MaxBlockNumber, p.MinBlockNumber)
+	}
+	if p.MinPlausibleProofTimeSec < 0 {
+		return fmt.Errorf("min_plausible_proof_time_sec must not be negative, got %d", p.MinPlausibleProofTimeSec)
+	}
+	if p.MaxAssignmentsPerSecond < 0 {
+		return fmt.Errorf("max_assignments_per_second must not be negative, got %v", p.MaxAssignmentsPerSecond)
+	}
+	if p.OrmQueryTimeoutMs < 0 {
+		return fmt.Errorf("orm_query_timeout_ms must not be negative, got %d", p.OrmQueryTimeoutMs)
+	}
+	if p.MinProverVersion == "" {
+		return errors.New("min_prover_version is empty")
+	}
+	if p.Verifier == nil {
+		return errors.New("verifier config is missing")
+	}
+	if !p.Verifier.MockMode && (p.Verifier.ParamsPath == "" || p.Verifier.AssetsPath == "") {
+		return errors.New("verifier params_path and assets_path are required when mock_mode is disabled")
+	}
+	return nil
+}
+
+// Validate checks the jwt secret and expire durations.
+func (a *Auth) Validate() error {
+	if a.Secret == "" {
+		return errors.New("secret is empty")
+	}
+	if a.ChallengeExpireDurationSec <= 0 {
+		return fmt.Errorf("challenge_expire_duration_sec must be positive, got %d", a.ChallengeExpireDurationSec)
+	}
+	if a.LoginExpireDurationSec <= 0 {
+		return fmt.Errorf("login_expire_duration_sec must be positive, got %d", a.LoginExpireDurationSec)
+	}
+	return nil
+}
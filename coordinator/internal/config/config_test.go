@@ -3,11 +3,15 @@ package config
 import (
 	"encoding/json"
 	"fmt"
+	"math"
 	"os"
+	"strings"
 	"testing"
 	"time"
 
 	"github.com/stretchr/testify/assert"
+
+	"scroll-tech/common/database"
 )
 
 func TestConfig(t *testing.T) {
@@ -89,4 +93,74 @@ func TestConfig(t *testing.T) {
 		_, err = NewConfig(tmpFile.Name())
 		assert.Error(t, err)
 	})
+
+	t.Run("Invalid Config Value", func(t *testing.T) {
+		tmpFile, err := os.CreateTemp("", "invalid_value_config.json")
+		assert.NoError(t, err)
+		defer func() {
+			assert.NoError(t, tmpFile.Close())
+			assert.NoError(t, os.Remove(tmpFile.Name()))
+		}()
+
+		invalidTemplate := strings.Replace(configTemplate, `"batch_collection_time_sec": 180`, `"batch_collection_time_sec": 0`, 1)
+		_, err = tmpFile.WriteString(invalidTemplate)
+		assert.NoError(t, err)
+
+		_, err = NewConfig(tmpFile.Name())
+		assert.ErrorContains(t, err, "batch_collection_time_sec must be positive")
+	})
+}
+
+func TestConfigValidate(t *testing.T) {
+	newValidConfig := func() *Config {
+		return &Config{
+			ProverManager: &ProverManager{
+				ProversPerSession:      1,
+				SessionAttempts:        5,
+				Verifier:               &VerifierConfig{MockMode: true},
+				BatchCollectionTimeSec: 180,
+				ChunkCollectionTimeSec: 180,
+				MaxVerifierWorkers:     4,
+				MinProverVersion:       "v1.0.0",
+			},
+			DB:   &database.Config{},
+			L2:   &L2{ChainID: 111},
+			Auth: &Auth{Secret: "prover secret key", ChallengeExpireDurationSec: 3600, LoginExpireDurationSec: 3600},
+		}
+	}
+	assert.NoError(t, newValidConfig().Validate())
+
+	testCases := []struct {
+		name   string
+		modify func(cfg *Config)
+		errMsg string
+	}{
+		{"missing prover manager", func(cfg *Config) { cfg.ProverManager = nil }, "prover_manager config is missing"},
+		{"zero provers per session", func(cfg *Config) { cfg.ProverManager.ProversPerSession = 0 }, "provers_per_session must be positive"},
+		{"zero session attempts", func(cfg *Config) { cfg.ProverManager.SessionAttempts = 0 }, "session_attempts must be positive"},
+		{"negative chunk collection time", func(cfg *Config) { cfg.ProverManager.ChunkCollectionTimeSec = -1 }, "chunk_collection_time_sec must be positive"},
+		{"max block number less than min", func(cfg *Config) {
+			cfg.ProverManager.MinBlockNumber = 100
+			cfg.ProverManager.MaxBlockNumber = 99
+		}, "max_block_number 99 is less than min_block_number 100"},
+		{"max block number overflow", func(cfg *Config) { cfg.ProverManager.MaxBlockNumber = math.MaxUint64 }, "max_block_number 18446744073709551615 must be less than 9223372036854775807"},
+		{"negative min plausible proof time", func(cfg *Config) { cfg.ProverManager.MinPlausibleProofTimeSec = -1 }, "min_plausible_proof_time_sec must not be negative"},
+		{"negative max assignments per second", func(cfg *Config) { cfg.ProverManager.MaxAssignmentsPerSecond = -1 }, "max_assignments_per_second must not be negative"},
+		{"negative orm query timeout", func(cfg *Config) { cfg.ProverManager.OrmQueryTimeoutMs = -1 }, "orm_query_timeout_ms must not be negative"},
+		{"empty min prover version", func(cfg *Config) { cfg.ProverManager.MinProverVersion = "" }, "min_prover_version is empty"},
+		{"missing verifier", func(cfg *Config) { cfg.ProverManager.Verifier = nil }, "verifier config is missing"},
+		{"verifier without assets", func(cfg *Config) { cfg.ProverManager.Verifier.MockMode = false }, "params_path and assets_path are required"},
+		{"missing db", func(cfg *Config) { cfg.DB = nil }, "db config is missing"},
+		{"missing l2", func(cfg *Config) { cfg.L2 = nil }, "l2 config is missing"},
+		{"empty auth secret", func(cfg *Config) { cfg.Auth.Secret = "" }, "secret is empty"},
+		{"zero login expire duration", func(cfg *Config) { cfg.Auth.LoginExpireDurationSec = 0 }, "login_expire_duration_sec must be positive"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			cfg := newValidConfig()
+			tc.modify(cfg)
+			assert.ErrorContains(t, cfg.Validate(), tc.errMsg)
+		})
+	}
 }
@@ -16,6 +16,7 @@ import (
 
 	"scroll-tech/coordinator/internal/config"
 	"scroll-tech/coordinator/internal/logic/provertask"
+	"scroll-tech/coordinator/internal/logic/ratelimit"
 	"scroll-tech/coordinator/internal/logic/verifier"
 	coordinatorType "scroll-tech/coordinator/internal/types"
 )
@@ -23,6 +24,7 @@ import (
 // GetTaskController the get prover task api controller
 type GetTaskController struct {
 	proverTasks map[message.ProofType]provertask.ProverTask
+	assignLimit *ratelimit.TokenBucket
 
 	getTaskAccessCounter *prometheus.CounterVec
 }
@@ -34,6 +36,7 @@ func NewGetTaskController(cfg *config.Config, chainCfg *params.ChainConfig, db *
 
 	ptc := &GetTaskController{
 		proverTasks: make(map[message.ProofType]provertask.ProverTask),
+		assignLimit: ratelimit.NewTokenBucket(cfg.ProverManager.MaxAssignmentsPerSecond, reg),
 		getTaskAccessCounter: promauto.With(reg).NewCounterVec(prometheus.CounterOpts{
 			Name: "coordinator_get_task_access_count",
 			Help: "Multi dimensions get task counter.",
@@ -89,14 +92,23 @@ func (ptc *GetTaskController) GetTasks(ctx *gin.Context) {
 		log.Warn("get_task access counter inc failed", "error", err.Error())
 	}
 
+	reservation := ptc.assignLimit.Reserve()
+	if reservation == nil {
+		nerr := fmt.Errorf("get empty prover task, assignment rate limit reached")
+		types.RenderFailure(ctx, types.ErrCoordinatorEmptyProofData, nerr)
+		return
+	}
+
 	result, err := proverTask.Assign(ctx, &getTaskParameter)
 	if err != nil {
+		reservation.Cancel()
 		nerr := fmt.Errorf("return prover task err:%w", err)
 		types.RenderFailure(ctx, types.ErrCoordinatorGetTaskFailure, nerr)
 		return
 	}
 
 	if result == nil {
+		reservation.Cancel()
 		nerr := fmt.Errorf("get empty prover task")
 		types.RenderFailure(ctx, types.ErrCoordinatorEmptyProofData, nerr)
 		return
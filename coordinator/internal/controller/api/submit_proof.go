@@ -2,6 +2,7 @@ package api
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
 
 	"github.com/gin-gonic/gin"
@@ -38,7 +39,23 @@ func (spc *SubmitProofController) SubmitProof(ctx *gin.Context) {
 		return
 	}
 
-	proofMsg := message.ProofMsg{
+	proofMsg, err := decodeProofMsg(&spp)
+	if err != nil {
+		types.RenderFailure(ctx, types.ErrCoordinatorParameterInvalidNo, err)
+		return
+	}
+
+	if err = spc.submitProofReceiverLogic.HandleZkProof(ctx, proofMsg, spp); err != nil {
+		nerr := fmt.Errorf("handle zk proof failure, err:%w", err)
+		types.RenderFailure(ctx, types.ErrCoordinatorHandleZkProofFailure, nerr)
+		return
+	}
+	types.RenderSuccess(ctx, nil)
+}
+
+// decodeProofMsg builds the proof msg from the submit proof parameter and validates it.
+func decodeProofMsg(spp *coordinatorType.SubmitProofParameter) (*message.ProofMsg, error) {
+	proofMsg := &message.ProofMsg{
 		ProofDetail: &message.ProofDetail{
 			ID:     spp.TaskID,
 			Type:   message.ProofType(spp.TaskType),
@@ -51,26 +68,58 @@ func (spc *SubmitProofController) SubmitProof(ctx *gin.Context) {
 		case message.ProofTypeChunk:
 			var tmpChunkProof message.ChunkProof
 			if err := json.Unmarshal([]byte(spp.Proof), &tmpChunkProof); err != nil {
-				nerr := fmt.Errorf("unmarshal parameter chunk proof invalid, err:%w", err)
-				types.RenderFailure(ctx, types.ErrCoordinatorParameterInvalidNo, nerr)
-				return
+				return nil, fmt.Errorf("unmarshal parameter chunk proof invalid, err:%w", err)
 			}
 			proofMsg.ChunkProof = &tmpChunkProof
 		case message.ProofTypeBatch:
 			var tmpBatchProof message.BatchProof
 			if err := json.Unmarshal([]byte(spp.Proof), &tmpBatchProof); err != nil {
-				nerr := fmt.Errorf("unmarshal parameter batch proof invalid, err:%w", err)
-				types.RenderFailure(ctx, types.ErrCoordinatorParameterInvalidNo, nerr)
-				return
+				return nil, fmt.Errorf("unmarshal parameter batch proof invalid, err:%w", err)
 			}
 			proofMsg.BatchProof = &tmpBatchProof
 		}
 	}
 
-	if err := spc.submitProofReceiverLogic.HandleZkProof(ctx, &proofMsg, spp); err != nil {
-		nerr := fmt.Errorf("handle zk proof failure, err:%w", err)
-		types.RenderFailure(ctx, types.ErrCoordinatorHandleZkProofFailure, nerr)
-		return
+	if err := validateProofMsg(proofMsg); err != nil {
+		return nil, fmt.Errorf("proof msg invalid, err:%w", err)
 	}
-	types.RenderSuccess(ctx, nil)
+	return proofMsg, nil
+}
+
+// validateProofMsg checks the structure of a proof msg before it is handled, so a malformed
+// submission is rejected without touching the database.
+func validateProofMsg(msg *message.ProofMsg) error {
+	if msg == nil || msg.ProofDetail == nil {
+		return errors.New("empty proof detail")
+	}
+	if msg.ID == "" {
+		return errors.New("empty task id")
+	}
+	if msg.Type != message.ProofTypeChunk && msg.Type != message.ProofTypeBatch {
+		return fmt.Errorf("unknown proof type:%d", msg.Type)
+	}
+
+	switch msg.Status {
+	case message.StatusOk:
+	case message.StatusProofError:
+		// a failed proof generation carries no proof
+		return nil
+	default:
+		return fmt.Errorf("unknown proof status:%d", msg.Status)
+	}
+
+	switch msg.Type {
+	case message.ProofTypeChunk:
+		if msg.ChunkProof == nil || len(msg.ChunkProof.Proof) == 0 {
+			return errors.New("empty chunk proof")
+		}
+	case message.ProofTypeBatch:
+		if msg.BatchProof == nil {
+			return errors.New("empty batch proof")
+		}
+		if err := msg.BatchProof.SanityCheck(); err != nil {
+			return fmt.Errorf("invalid batch proof: %w", err)
+		}
+	}
+	return nil
 }
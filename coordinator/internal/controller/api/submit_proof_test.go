@@ -0,0 +1,117 @@
+package api
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+
+	"scroll-tech/common/types"
+	"scroll-tech/common/types/message"
+
+	coordinatorType "scroll-tech/coordinator/internal/types"
+)
+
+func TestValidateProofMsg(t *testing.T) {
+	validChunkProof := &message.ChunkProof{Proof: []byte("proof")}
+	validBatchProof := &message.BatchProof{Proof: make([]byte, 32)}
+
+	testCases := []struct {
+		name   string
+		msg    *message.ProofMsg
+		errMsg string
+	}{
+		{"nil msg", nil, "empty proof detail"},
+		{"nil proof detail", &message.ProofMsg{}, "empty proof detail"},
+		{"empty task id", &message.ProofMsg{ProofDetail: &message.ProofDetail{Type: message.ProofTypeChunk, ChunkProof: validChunkProof}}, "empty task id"},
+		{"undefined proof type", &message.ProofMsg{ProofDetail: &message.ProofDetail{ID: "hash", Type: message.ProofTypeUndefined}}, "unknown proof type"},
+		{"unknown proof type", &message.ProofMsg{ProofDetail: &message.ProofDetail{ID: "hash", Type: message.ProofType(3)}}, "unknown proof type"},
+		{"unknown status", &message.ProofMsg{ProofDetail: &message.ProofDetail{ID: "hash", Type: message.ProofTypeChunk, Status: message.RespStatus(2), ChunkProof: validChunkProof}}, "unknown proof status"},
+		{"nil chunk proof", &message.ProofMsg{ProofDetail: &message.ProofDetail{ID: "hash", Type: message.ProofTypeChunk}}, "empty chunk proof"},
+		{"empty chunk proof", &message.ProofMsg{ProofDetail: &message.ProofDetail{ID: "hash", Type: message.ProofTypeChunk, ChunkProof: &message.ChunkProof{}}}, "empty chunk proof"},
+		{"chunk task with batch proof", &message.ProofMsg{ProofDetail: &message.ProofDetail{ID: "hash", Type: message.ProofTypeChunk, BatchProof: validBatchProof}}, "empty chunk proof"},
+		{"nil batch proof", &message.ProofMsg{ProofDetail: &message.ProofDetail{ID: "hash", Type: message.ProofTypeBatch}}, "empty batch proof"},
+		{"empty batch proof", &message.ProofMsg{ProofDetail: &message.ProofDetail{ID: "hash", Type: message.ProofTypeBatch, BatchProof: &message.BatchProof{}}}, "proof not ready"},
+		{"wrong length batch proof", &message.ProofMsg{ProofDetail: &message.ProofDetail{ID: "hash", Type: message.ProofTypeBatch, BatchProof: &message.BatchProof{Proof: []byte("proof")}}}, "proof buffer has wrong length"},
+		{"valid chunk proof", &message.ProofMsg{ProofDetail: &message.ProofDetail{ID: "hash", Type: message.ProofTypeChunk, ChunkProof: validChunkProof}}, ""},
+		{"valid batch proof", &message.ProofMsg{ProofDetail: &message.ProofDetail{ID: "hash", Type: message.ProofTypeBatch, BatchProof: validBatchProof}}, ""},
+		{"failed proof without proof", &message.ProofMsg{ProofDetail: &message.ProofDetail{ID: "hash", Type: message.ProofTypeBatch, Status: message.StatusProofError}}, ""},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := validateProofMsg(tc.msg)
+			if tc.errMsg == "" {
+				assert.NoError(t, err)
+				return
+			}
+			assert.ErrorContains(t, err, tc.errMsg)
+		})
+	}
+}
+
+func TestSubmitProofInvalidProofMsg(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	// the proof receiver logic is nil, so the request must be rejected before reaching it.
+	spc := &SubmitProofController{}
+
+	testCases := []struct {
+		name  string
+		param coordinatorType.SubmitProofParameter
+	}{
+		{"empty chunk proof", coordinatorType.SubmitProofParameter{TaskID: "hash", TaskType: int(message.ProofTypeChunk), Proof: "{}"}},
+		{"empty batch proof", coordinatorType.SubmitProofParameter{TaskID: "hash", TaskType: int(message.ProofTypeBatch), Proof: `{"proof":""}`}},
+		{"wrong length batch proof", coordinatorType.SubmitProofParameter{TaskID: "hash", TaskType: int(message.ProofTypeBatch), Proof: `{"proof":"cHJvb2Y="}`}},
+		{"unknown proof type", coordinatorType.SubmitProofParameter{TaskID: "hash", TaskType: 3, Status: int(message.StatusProofError)}},
+		{"unknown status", coordinatorType.SubmitProofParameter{TaskID: "hash", TaskType: int(message.ProofTypeBatch), Status: 2}},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			body, err := json.Marshal(tc.param)
+			assert.NoError(t, err)
+
+			w := httptest.NewRecorder()
+			ctx, _ := gin.CreateTestContext(w)
+			ctx.Request = httptest.NewRequest(http.MethodPost, "/coordinator/v1/submit_proof", bytes.NewReader(body))
+			ctx.Request.Header.Set("Content-Type", "application/json")
+
+			spc.SubmitProof(ctx)
+
+			var resp types.Response
+			assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+			assert.Equal(t, types.ErrCoordinatorParameterInvalidNo, resp.ErrCode)
+			assert.Contains(t, resp.ErrMsg, "proof msg invalid")
+		})
+	}
+}
+
+func FuzzDecodeProofMsg(f *testing.F) {
+	f.Add("hash", int(message.ProofTypeChunk), int(message.StatusOk), `{"proof":"cHJvb2Y="}`)
+	f.Add("hash", int(message.ProofTypeBatch), int(message.StatusOk), `{"proof":"AAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAA=","instances":"aW5zdGFuY2Vz"}`)
+	f.Add("hash", int(message.ProofTypeBatch), int(message.StatusProofError), "")
+	f.Add("", 0, 0, "")
+	f.Add("hash", int(message.ProofTypeChunk), int(message.StatusOk), `{"proof":"cHJv`)
+	f.Add("hash", int(message.ProofTypeChunk), int(message.StatusOk), `{"unknown_field":1}`)
+	f.Add("hash", -1, -1, "null")
+
+	f.Fuzz(func(t *testing.T, taskID string, taskType int, status int, proof string) {
+		spp := &coordinatorType.SubmitProofParameter{TaskID: taskID, TaskType: taskType, Status: status, Proof: proof}
+		proofMsg, err := decodeProofMsg(spp)
+		if err != nil {
+			assert.Nil(t, proofMsg)
+			return
+		}
+
+		// a decoded proof msg must pass validation, and a successful one must carry a proof
+		assert.NoError(t, validateProofMsg(proofMsg))
+		assert.Equal(t, taskID, proofMsg.ID)
+		if proofMsg.Status == message.StatusOk {
+			assert.True(t, proofMsg.ChunkProof != nil || proofMsg.BatchProof != nil)
+		}
+	})
+}
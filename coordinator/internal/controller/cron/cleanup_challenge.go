@@ -22,7 +22,11 @@ func (c *Collector) cleanupChallenge() {
 		select {
 		case <-ticker.C:
 			expiredTime := utils.NowUTC().Add(-time.Hour)
-			if err := c.challenge.DeleteExpireChallenge(c.ctx, expiredTime); err != nil {
+			ctx, cancel := c.queryContext()
+			err := c.challenge.DeleteExpireChallenge(ctx, expiredTime)
+			cancel()
+			if err != nil {
+				c.countQueryTimeout(err)
 				log.Error("delete expired challenge failure", "error", err)
 			}
 		case <-c.ctx.Done():
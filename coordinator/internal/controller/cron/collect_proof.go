@@ -2,6 +2,7 @@ package cron
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"time"
 
@@ -38,6 +39,7 @@ type Collector struct {
 	timeoutChunkCheckerRunTotal     prometheus.Counter
 	chunkProverTaskTimeoutTotal     prometheus.Counter
 	checkBatchAllChunkReadyRunTotal prometheus.Counter
+	ormTimeoutTotal                 prometheus.Counter
 }
 
 // NewCollector create a collector to cron collect the data to send to prover
@@ -75,6 +77,10 @@ func NewCollector(ctx context.Context, db *gorm.DB, cfg *config.Config, reg prom
 			Name: "coordinator_check_batch_all_chunk_ready_run_total",
 			Help: "Total number of check batch all chunks ready total",
 		}),
+		ormTimeoutTotal: promauto.With(reg).NewCounter(prometheus.CounterOpts{
+			Name: "coordinator_orm_timeouts_total",
+			Help: "Total number of database queries of the cron checkers reaching orm_query_timeout_ms.",
+		}),
 	}
 
 	go c.timeoutBatchProofTask()
@@ -95,6 +101,20 @@ func (c *Collector) Stop() {
 	c.stopCleanChallengeChan <- struct{}{}
 }
 
+// queryContext returns the context for a single database query, bounded by orm_query_timeout_ms if configured.
+func (c *Collector) queryContext() (context.Context, context.CancelFunc) {
+	if c.cfg.ProverManager.OrmQueryTimeoutMs <= 0 {
+		return context.WithCancel(c.ctx)
+	}
+	return context.WithTimeout(c.ctx, time.Duration(c.cfg.ProverManager.OrmQueryTimeoutMs)*time.Millisecond)
+}
+
+func (c *Collector) countQueryTimeout(err error) {
+	if errors.Is(err, context.DeadlineExceeded) {
+		c.ormTimeoutTotal.Inc()
+	}
+}
+
 // timeoutBatchProofTask cron check the send task is timeout. if timeout reached, restore the
 // chunk/batch task to unassigned. then the batch/chunk collector can retry it.
 func (c *Collector) timeoutBatchProofTask() {
@@ -111,8 +131,11 @@ func (c *Collector) timeoutBatchProofTask() {
 		case <-ticker.C:
 			c.timeoutBatchCheckerRunTotal.Inc()
 			timeout := time.Duration(c.cfg.ProverManager.BatchCollectionTimeSec) * time.Second
-			assignedProverTasks, err := c.proverTaskOrm.GetTimeoutAssignedProverTasks(c.ctx, 10, message.ProofTypeBatch, timeout)
+			ctx, cancel := c.queryContext()
+			assignedProverTasks, err := c.proverTaskOrm.GetTimeoutAssignedProverTasks(ctx, 10, message.ProofTypeBatch, timeout)
+			cancel()
 			if err != nil {
+				c.countQueryTimeout(err)
 				log.Error("get unassigned session info failure", "error", err)
 				break
 			}
@@ -143,8 +166,11 @@ func (c *Collector) timeoutChunkProofTask() {
 		case <-ticker.C:
 			c.timeoutChunkCheckerRunTotal.Inc()
 			timeout := time.Duration(c.cfg.ProverManager.ChunkCollectionTimeSec) * time.Second
-			assignedProverTasks, err := c.proverTaskOrm.GetTimeoutAssignedProverTasks(c.ctx, 10, message.ProofTypeChunk, timeout)
+			ctx, cancel := c.queryContext()
+			assignedProverTasks, err := c.proverTaskOrm.GetTimeoutAssignedProverTasks(ctx, 10, message.ProofTypeChunk, timeout)
+			cancel()
 			if err != nil {
+				c.countQueryTimeout(err)
 				log.Error("get unassigned session info failure", "error", err)
 				break
 			}
@@ -166,49 +192,57 @@ func (c *Collector) check(assignedProverTasks []orm.ProverTask, timeout promethe
 	// here not update the block batch proving status failed, because the collector loop will check
 	// the attempt times. if reach the times, the collector will set the block batch proving status.
 	for _, assignedProverTask := range assignedProverTasks {
-		if c.proverTaskOrm.TaskTimeoutMoreThanOnce(c.ctx, message.ProofType(assignedProverTask.TaskType), assignedProverTask.TaskID) {
-			log.Warn("Task timeout more than once", "taskType", message.ProofType(assignedProverTask.TaskType).String(), "hash", assignedProverTask.TaskID)
-		}
+		c.checkTimeoutTask(assignedProverTask, timeout)
+	}
+}
 
-		timeout.Inc()
+func (c *Collector) checkTimeoutTask(assignedProverTask orm.ProverTask, timeout prometheus.Counter) {
+	ctx, cancel := c.queryContext()
+	defer cancel()
 
-		log.Warn("proof task have reach the timeout", "task id", assignedProverTask.TaskID,
-			"prover public key", assignedProverTask.ProverPublicKey, "prover name", assignedProverTask.ProverName, "task type", assignedProverTask.TaskType)
+	if c.proverTaskOrm.TaskTimeoutMoreThanOnce(ctx, message.ProofType(assignedProverTask.TaskType), assignedProverTask.TaskID) {
+		log.Warn("Task timeout more than once", "taskType", message.ProofType(assignedProverTask.TaskType).String(), "hash", assignedProverTask.TaskID)
+	}
 
-		err := c.db.Transaction(func(tx *gorm.DB) error {
-			if err := c.proverTaskOrm.UpdateProverTaskProvingStatusAndFailureType(c.ctx, assignedProverTask.UUID, types.ProverProofInvalid, types.ProverTaskFailureTypeTimeout, tx); err != nil {
-				log.Error("update prover task proving status failure", "uuid", assignedProverTask.UUID, "hash", assignedProverTask.TaskID, "pubKey", assignedProverTask.ProverPublicKey, "err", err)
-				return err
-			}
+	timeout.Inc()
 
-			switch message.ProofType(assignedProverTask.TaskType) {
-			case message.ProofTypeChunk:
-				if err := c.chunkOrm.DecreaseActiveAttemptsByHash(c.ctx, assignedProverTask.TaskID, tx); err != nil {
-					log.Error("decrease chunk active attempts failure", "uuid", assignedProverTask.UUID, "hash", assignedProverTask.TaskID, "pubKey", assignedProverTask.ProverPublicKey, "err", err)
-					return err
-				}
+	log.Warn("proof task have reach the timeout", "task id", assignedProverTask.TaskID,
+		"prover public key", assignedProverTask.ProverPublicKey, "prover name", assignedProverTask.ProverName, "task type", assignedProverTask.TaskType)
 
-				if err := c.chunkOrm.UpdateProvingStatusFailed(c.ctx, assignedProverTask.TaskID, c.cfg.ProverManager.SessionAttempts, tx); err != nil {
-					log.Error("update proving status failed failure", "uuid", assignedProverTask.UUID, "hash", assignedProverTask.TaskID, "pubKey", assignedProverTask.ProverPublicKey, "err", err)
-					return err
-				}
-			case message.ProofTypeBatch:
-				if err := c.batchOrm.DecreaseActiveAttemptsByHash(c.ctx, assignedProverTask.TaskID, tx); err != nil {
-					log.Error("decrease batch active attempts failure", "uuid", assignedProverTask.UUID, "hash", assignedProverTask.TaskID, "pubKey", assignedProverTask.ProverPublicKey, "err", err)
-					return err
-				}
+	err := c.db.Transaction(func(tx *gorm.DB) error {
+		if err := c.proverTaskOrm.UpdateProverTaskProvingStatusAndFailureType(ctx, assignedProverTask.UUID, types.ProverProofInvalid, types.ProverTaskFailureTypeTimeout, tx); err != nil {
+			log.Error("update prover task proving status failure", "uuid", assignedProverTask.UUID, "hash", assignedProverTask.TaskID, "pubKey", assignedProverTask.ProverPublicKey, "err", err)
+			return err
+		}
 
-				if err := c.batchOrm.UpdateProvingStatusFailed(c.ctx, assignedProverTask.TaskID, c.cfg.ProverManager.SessionAttempts, tx); err != nil {
-					log.Error("update proving status failed failure", "uuid", assignedProverTask.UUID, "hash", assignedProverTask.TaskID, "pubKey", assignedProverTask.ProverPublicKey, "err", err)
-					return err
-				}
+		switch message.ProofType(assignedProverTask.TaskType) {
+		case message.ProofTypeChunk:
+			if err := c.chunkOrm.DecreaseActiveAttemptsByHash(ctx, assignedProverTask.TaskID, tx); err != nil {
+				log.Error("decrease chunk active attempts failure", "uuid", assignedProverTask.UUID, "hash", assignedProverTask.TaskID, "pubKey", assignedProverTask.ProverPublicKey, "err", err)
+				return err
+			}
+
+			if err := c.chunkOrm.UpdateProvingStatusFailed(ctx, assignedProverTask.TaskID, c.cfg.ProverManager.SessionAttempts, tx); err != nil {
+				log.Error("update proving status failed failure", "uuid", assignedProverTask.UUID, "hash", assignedProverTask.TaskID, "pubKey", assignedProverTask.ProverPublicKey, "err", err)
+				return err
+			}
+		case message.ProofTypeBatch:
+			if err := c.batchOrm.DecreaseActiveAttemptsByHash(ctx, assignedProverTask.TaskID, tx); err != nil {
+				log.Error("decrease batch active attempts failure", "uuid", assignedProverTask.UUID, "hash", assignedProverTask.TaskID, "pubKey", assignedProverTask.ProverPublicKey, "err", err)
+				return err
 			}
 
-			return nil
-		})
-		if err != nil {
-			log.Error("check task proof is timeout failure", "error", err)
+			if err := c.batchOrm.UpdateProvingStatusFailed(ctx, assignedProverTask.TaskID, c.cfg.ProverManager.SessionAttempts, tx); err != nil {
+				log.Error("update proving status failed failure", "uuid", assignedProverTask.UUID, "hash", assignedProverTask.TaskID, "pubKey", assignedProverTask.ProverPublicKey, "err", err)
+				return err
+			}
 		}
+
+		return nil
+	})
+	if err != nil {
+		c.countQueryTimeout(err)
+		log.Error("check task proof is timeout failure", "error", err)
 	}
 }
 
@@ -229,15 +263,19 @@ func (c *Collector) checkBatchAllChunkReady() {
 			pageSize := 50
 			for {
 				offset := (page - 1) * pageSize
-				batches, err := c.batchOrm.GetUnassignedAndChunksUnreadyBatches(c.ctx, offset, pageSize)
+				ctx, cancel := c.queryContext()
+				batches, err := c.batchOrm.GetUnassignedAndChunksUnreadyBatches(ctx, offset, pageSize)
+				cancel()
 				if err != nil {
+					c.countQueryTimeout(err)
 					log.Warn("checkBatchAllChunkReady GetUnassignedAndChunksUnreadyBatches", "error", err)
 					break
 				}
 
 				for _, batch := range batches {
-					allReady, checkErr := c.chunkOrm.CheckIfBatchChunkProofsAreReady(c.ctx, batch.Hash)
+					allReady, checkErr := c.checkIfBatchChunkProofsAreReady(batch.Hash)
 					if checkErr != nil {
+						c.countQueryTimeout(checkErr)
 						log.Warn("checkBatchAllChunkReady CheckIfBatchChunkProofsAreReady failure", "error", checkErr, "hash", batch.Hash)
 						continue
 					}
@@ -246,8 +284,9 @@ func (c *Collector) checkBatchAllChunkReady() {
 						continue
 					}
 
-					if updateErr := c.batchOrm.UpdateChunkProofsStatusByBatchHash(c.ctx, batch.Hash, types.ChunkProofsStatusReady); updateErr != nil {
-						log.Warn("checkBatchAllChunkReady UpdateChunkProofsStatusByBatchHash failure", "error", checkErr, "hash", batch.Hash)
+					if updateErr := c.updateChunkProofsStatusReady(batch.Hash); updateErr != nil {
+						c.countQueryTimeout(updateErr)
+						log.Warn("checkBatchAllChunkReady UpdateChunkProofsStatusByBatchHash failure", "error", updateErr, "hash", batch.Hash)
 					}
 				}
 
@@ -268,3 +307,15 @@ func (c *Collector) checkBatchAllChunkReady() {
 		}
 	}
 }
+
+func (c *Collector) checkIfBatchChunkProofsAreReady(batchHash string) (bool, error) {
+	ctx, cancel := c.queryContext()
+	defer cancel()
+	return c.chunkOrm.CheckIfBatchChunkProofsAreReady(ctx, batchHash)
+}
+
+func (c *Collector) updateChunkProofsStatusReady(batchHash string) error {
+	ctx, cancel := c.queryContext()
+	defer cancel()
+	return c.batchOrm.UpdateChunkProofsStatusByBatchHash(ctx, batchHash, types.ChunkProofsStatusReady)
+}
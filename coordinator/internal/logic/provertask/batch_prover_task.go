@@ -21,6 +21,7 @@ import (
 	"scroll-tech/common/utils"
 
 	"scroll-tech/coordinator/internal/config"
+	"scroll-tech/coordinator/internal/logic/querytimeout"
 	"scroll-tech/coordinator/internal/orm"
 	coordinatorType "scroll-tech/coordinator/internal/types"
 )
@@ -32,6 +33,7 @@ type BatchProverTask struct {
 	batchAttemptsExceedTotal prometheus.Counter
 	batchTaskGetTaskTotal    *prometheus.CounterVec
 	batchTaskGetTaskProver   *prometheus.CounterVec
+	batchAssignmentLatency   prometheus.Observer
 }
 
 // NewBatchProverTask new a batch collector
@@ -50,6 +52,7 @@ func NewBatchProverTask(cfg *config.Config, chainCfg *params.ChainConfig, db *go
 			batchOrm:           orm.NewBatch(db),
 			proverTaskOrm:      orm.NewProverTask(db),
 			proverBlockListOrm: orm.NewProverBlockList(db),
+			queryTimeout:       querytimeout.NewQueryTimeout(cfg.ProverManager.OrmQueryTimeoutMs, reg),
 		},
 		batchAttemptsExceedTotal: promauto.With(reg).NewCounter(prometheus.CounterOpts{
 			Name: "coordinator_batch_attempts_exceed_total",
@@ -60,6 +63,7 @@ func NewBatchProverTask(cfg *config.Config, chainCfg *params.ChainConfig, db *go
 			Help: "Total number of batch get task.",
 		}, []string{"fork_name"}),
 		batchTaskGetTaskProver: newGetTaskCounterVec(promauto.With(reg), "batch"),
+		batchAssignmentLatency: newAssignmentLatencyHistogram(promauto.With(reg), "batch"),
 	}
 	return bp
 }
@@ -83,8 +87,11 @@ func (bp *BatchProverTask) Assign(ctx *gin.Context, getTaskParameter *coordinato
 	var endChunkIndex uint64 = math.MaxInt64
 	fromBlockNum, toBlockNum := forks.BlockRange(hardForkNumber, bp.forkHeights)
 	if fromBlockNum != 0 {
-		startChunk, chunkErr := bp.chunkOrm.GetChunkByStartBlockNumber(ctx.Copy(), fromBlockNum)
+		queryCtx, cancel := bp.queryTimeout.Context(ctx.Copy())
+		startChunk, chunkErr := bp.chunkOrm.GetChunkByStartBlockNumber(queryCtx, fromBlockNum)
+		cancel()
 		if chunkErr != nil {
+			bp.queryTimeout.Count(chunkErr)
 			log.Error("failed to get fork start chunk index", "forkName", taskCtx.HardForkName, "fromBlockNumber", fromBlockNum, "err", chunkErr)
 			return nil, ErrCoordinatorInternalFailure
 		}
@@ -94,8 +101,11 @@ func (bp *BatchProverTask) Assign(ctx *gin.Context, getTaskParameter *coordinato
 		startChunkIndex = startChunk.Index
 	}
 	if toBlockNum != math.MaxInt64 {
-		toChunk, chunkErr := bp.chunkOrm.GetChunkByStartBlockNumber(ctx.Copy(), toBlockNum)
+		queryCtx, cancel := bp.queryTimeout.Context(ctx.Copy())
+		toChunk, chunkErr := bp.chunkOrm.GetChunkByStartBlockNumber(queryCtx, toBlockNum)
+		cancel()
 		if chunkErr != nil {
+			bp.queryTimeout.Count(chunkErr)
 			log.Error("failed to get fork end chunk index", "forkName", taskCtx.HardForkName, "toBlockNumber", toBlockNum, "err", chunkErr)
 			return nil, ErrCoordinatorInternalFailure
 		}
@@ -106,14 +116,49 @@ func (bp *BatchProverTask) Assign(ctx *gin.Context, getTaskParameter *coordinato
 		}
 	}
 
+	// only assign the batches whose chunks are within the coordinator's block number range
+	if minBlockNum := bp.cfg.ProverManager.MinBlockNumber; minBlockNum != 0 {
+		queryCtx, cancel := bp.queryTimeout.Context(ctx.Copy())
+		minChunk, chunkErr := bp.chunkOrm.GetFirstChunkStartingFrom(queryCtx, minBlockNum)
+		cancel()
+		if chunkErr != nil {
+			bp.queryTimeout.Count(chunkErr)
+			log.Error("failed to get min block number chunk index", "minBlockNumber", minBlockNum, "err", chunkErr)
+			return nil, ErrCoordinatorInternalFailure
+		}
+		if minChunk == nil {
+			return nil, nil
+		}
+		if minChunk.Index > startChunkIndex {
+			startChunkIndex = minChunk.Index
+		}
+	}
+	if maxBlockNum := bp.cfg.ProverManager.MaxBlockNumber; maxBlockNum != 0 {
+		queryCtx, cancel := bp.queryTimeout.Context(ctx.Copy())
+		maxChunk, chunkErr := bp.chunkOrm.GetFirstChunkEndingAfter(queryCtx, maxBlockNum)
+		cancel()
+		if chunkErr != nil {
+			bp.queryTimeout.Count(chunkErr)
+			log.Error("failed to get max block number chunk index", "maxBlockNumber", maxBlockNum, "err", chunkErr)
+			return nil, ErrCoordinatorInternalFailure
+		}
+		// maxChunk being nil indicates that no chunk goes beyond the max block number yet
+		if maxChunk != nil && maxChunk.Index < endChunkIndex {
+			endChunkIndex = maxChunk.Index
+		}
+	}
+
 	maxActiveAttempts := bp.cfg.ProverManager.ProversPerSession
 	maxTotalAttempts := bp.cfg.ProverManager.SessionAttempts
 	var batchTask *orm.Batch
 	for i := 0; i < 5; i++ {
 		var getTaskError error
 		var tmpBatchTask *orm.Batch
-		tmpBatchTask, getTaskError = bp.batchOrm.GetAssignedBatch(ctx.Copy(), startChunkIndex, endChunkIndex, maxActiveAttempts, maxTotalAttempts)
+		queryCtx, cancel := bp.queryTimeout.Context(ctx.Copy())
+		tmpBatchTask, getTaskError = bp.batchOrm.GetAssignedBatch(queryCtx, startChunkIndex, endChunkIndex, maxActiveAttempts, maxTotalAttempts)
+		cancel()
 		if getTaskError != nil {
+			bp.queryTimeout.Count(getTaskError)
 			log.Error("failed to get assigned batch proving tasks", "height", getTaskParameter.ProverHeight, "err", getTaskError)
 			return nil, ErrCoordinatorInternalFailure
 		}
@@ -121,8 +166,11 @@ func (bp *BatchProverTask) Assign(ctx *gin.Context, getTaskParameter *coordinato
 		// Why here need get again? In order to support a task can assign to multiple prover, need also assign `ProvingTaskAssigned`
 		// batch to prover. But use `proving_status in (1, 2)` will not use the postgres index. So need split the sql.
 		if tmpBatchTask == nil {
-			tmpBatchTask, getTaskError = bp.batchOrm.GetUnassignedBatch(ctx.Copy(), startChunkIndex, endChunkIndex, maxActiveAttempts, maxTotalAttempts)
+			queryCtx, cancel = bp.queryTimeout.Context(ctx.Copy())
+			tmpBatchTask, getTaskError = bp.batchOrm.GetUnassignedBatch(queryCtx, startChunkIndex, endChunkIndex, maxActiveAttempts, maxTotalAttempts)
+			cancel()
 			if getTaskError != nil {
+				bp.queryTimeout.Count(getTaskError)
 				log.Error("failed to get unassigned batch proving tasks", "height", getTaskParameter.ProverHeight, "err", getTaskError)
 				return nil, ErrCoordinatorInternalFailure
 			}
@@ -133,8 +181,11 @@ func (bp *BatchProverTask) Assign(ctx *gin.Context, getTaskParameter *coordinato
 			return nil, nil
 		}
 
-		rowsAffected, updateAttemptsErr := bp.batchOrm.UpdateBatchAttempts(ctx.Copy(), tmpBatchTask.Index, tmpBatchTask.ActiveAttempts, tmpBatchTask.TotalAttempts)
+		queryCtx, cancel = bp.queryTimeout.Context(ctx.Copy())
+		rowsAffected, updateAttemptsErr := bp.batchOrm.UpdateBatchAttempts(queryCtx, tmpBatchTask.Index, tmpBatchTask.ActiveAttempts, tmpBatchTask.TotalAttempts)
+		cancel()
 		if updateAttemptsErr != nil {
+			bp.queryTimeout.Count(updateAttemptsErr)
 			log.Error("failed to update batch attempts", "height", getTaskParameter.ProverHeight, "err", updateAttemptsErr)
 			return nil, ErrCoordinatorInternalFailure
 		}
@@ -168,19 +219,32 @@ func (bp *BatchProverTask) Assign(ctx *gin.Context, getTaskParameter *coordinato
 	}
 
 	// Store session info.
-	if err = bp.proverTaskOrm.InsertProverTask(ctx.Copy(), &proverTask); err != nil {
+	queryCtx, cancel := bp.queryTimeout.Context(ctx.Copy())
+	err = bp.proverTaskOrm.InsertProverTask(queryCtx, &proverTask)
+	cancel()
+	if err != nil {
+		bp.queryTimeout.Count(err)
 		bp.recoverActiveAttempts(ctx, batchTask)
 		log.Error("insert batch prover task info fail", "taskID", batchTask.Hash, "publicKey", taskCtx.PublicKey, "err", err)
 		return nil, ErrCoordinatorInternalFailure
 	}
 
-	taskMsg, err := bp.formatProverTask(ctx.Copy(), &proverTask)
+	queryCtx, cancel = bp.queryTimeout.Context(ctx.Copy())
+	taskMsg, err := bp.formatProverTask(queryCtx, &proverTask)
+	cancel()
 	if err != nil {
+		bp.queryTimeout.Count(err)
 		bp.recoverActiveAttempts(ctx, batchTask)
 		log.Error("format prover task failure", "hash", batchTask.Hash, "err", err)
 		return nil, ErrCoordinatorInternalFailure
 	}
 
+	// only the first assignment measures how long the task waited for a prover, prover_assigned_at is
+	// set by the first assignment and kept by the later ones
+	if batchTask.ProverAssignedAt == nil {
+		bp.batchAssignmentLatency.Observe(time.Since(batchTask.CreatedAt).Seconds())
+	}
+
 	bp.batchTaskGetTaskTotal.WithLabelValues(taskCtx.HardForkName).Inc()
 	bp.batchTaskGetTaskProver.With(prometheus.Labels{
 		coordinatorType.LabelProverName:      proverTask.ProverName,
@@ -242,7 +306,10 @@ func (bp *BatchProverTask) formatProverTask(ctx context.Context, task *orm.Prove
 }
 
 func (bp *BatchProverTask) recoverActiveAttempts(ctx *gin.Context, batchTask *orm.Batch) {
-	if err := bp.chunkOrm.DecreaseActiveAttemptsByHash(ctx.Copy(), batchTask.Hash); err != nil {
+	queryCtx, cancel := bp.queryTimeout.Context(ctx.Copy())
+	defer cancel()
+	if err := bp.chunkOrm.DecreaseActiveAttemptsByHash(queryCtx, batchTask.Hash); err != nil {
+		bp.queryTimeout.Count(err)
 		log.Error("failed to recover batch active attempts", "hash", batchTask.Hash, "error", err)
 	}
 }
@@ -19,6 +19,7 @@ import (
 	"scroll-tech/common/utils"
 
 	"scroll-tech/coordinator/internal/config"
+	"scroll-tech/coordinator/internal/logic/querytimeout"
 	"scroll-tech/coordinator/internal/orm"
 	coordinatorType "scroll-tech/coordinator/internal/types"
 )
@@ -30,6 +31,7 @@ type ChunkProverTask struct {
 	chunkAttemptsExceedTotal prometheus.Counter
 	chunkTaskGetTaskTotal    *prometheus.CounterVec
 	chunkTaskGetTaskProver   *prometheus.CounterVec
+	chunkAssignmentLatency   prometheus.Observer
 }
 
 // NewChunkProverTask new a chunk prover task
@@ -47,6 +49,7 @@ func NewChunkProverTask(cfg *config.Config, chainCfg *params.ChainConfig, db *go
 			blockOrm:           orm.NewL2Block(db),
 			proverTaskOrm:      orm.NewProverTask(db),
 			proverBlockListOrm: orm.NewProverBlockList(db),
+			queryTimeout:       querytimeout.NewQueryTimeout(cfg.ProverManager.OrmQueryTimeoutMs, reg),
 		},
 		chunkAttemptsExceedTotal: promauto.With(reg).NewCounter(prometheus.CounterOpts{
 			Name: "coordinator_chunk_attempts_exceed_total",
@@ -57,6 +60,7 @@ func NewChunkProverTask(cfg *config.Config, chainCfg *params.ChainConfig, db *go
 			Help: "Total number of chunk get task.",
 		}, []string{"fork_name"}),
 		chunkTaskGetTaskProver: newGetTaskCounterVec(promauto.With(reg), "chunk"),
+		chunkAssignmentLatency: newAssignmentLatencyHistogram(promauto.With(reg), "chunk"),
 	}
 	return cp
 }
@@ -79,14 +83,29 @@ func (cp *ChunkProverTask) Assign(ctx *gin.Context, getTaskParameter *coordinato
 		toBlockNum = getTaskParameter.ProverHeight + 1
 	}
 
+	// only assign the chunks within the coordinator's block number range
+	if fromBlockNum < cp.cfg.ProverManager.MinBlockNumber {
+		fromBlockNum = cp.cfg.ProverManager.MinBlockNumber
+	}
+	if cp.cfg.ProverManager.MaxBlockNumber != 0 && toBlockNum > cp.cfg.ProverManager.MaxBlockNumber+1 {
+		toBlockNum = cp.cfg.ProverManager.MaxBlockNumber + 1
+	}
+	if fromBlockNum >= toBlockNum {
+		log.Debug("chunk block range out of coordinator scope", "fromBlockNum", fromBlockNum, "toBlockNum", toBlockNum)
+		return nil, nil
+	}
+
 	maxActiveAttempts := cp.cfg.ProverManager.ProversPerSession
 	maxTotalAttempts := cp.cfg.ProverManager.SessionAttempts
 	var chunkTask *orm.Chunk
 	for i := 0; i < 5; i++ {
 		var getTaskError error
 		var tmpChunkTask *orm.Chunk
-		tmpChunkTask, getTaskError = cp.chunkOrm.GetAssignedChunk(ctx.Copy(), fromBlockNum, toBlockNum, maxActiveAttempts, maxTotalAttempts)
+		queryCtx, cancel := cp.queryTimeout.Context(ctx.Copy())
+		tmpChunkTask, getTaskError = cp.chunkOrm.GetAssignedChunk(queryCtx, fromBlockNum, toBlockNum, maxActiveAttempts, maxTotalAttempts)
+		cancel()
 		if getTaskError != nil {
+			cp.queryTimeout.Count(getTaskError)
 			log.Error("failed to get assigned chunk proving tasks", "height", getTaskParameter.ProverHeight, "err", getTaskError)
 			return nil, ErrCoordinatorInternalFailure
 		}
@@ -94,8 +113,11 @@ func (cp *ChunkProverTask) Assign(ctx *gin.Context, getTaskParameter *coordinato
 		// Why here need get again? In order to support a task can assign to multiple prover, need also assign `ProvingTaskAssigned`
 		// chunk to prover. But use `proving_status in (1, 2)` will not use the postgres index. So need split the sql.
 		if tmpChunkTask == nil {
-			tmpChunkTask, getTaskError = cp.chunkOrm.GetUnassignedChunk(ctx.Copy(), fromBlockNum, toBlockNum, maxActiveAttempts, maxTotalAttempts)
+			queryCtx, cancel = cp.queryTimeout.Context(ctx.Copy())
+			tmpChunkTask, getTaskError = cp.chunkOrm.GetUnassignedChunk(queryCtx, fromBlockNum, toBlockNum, maxActiveAttempts, maxTotalAttempts)
+			cancel()
 			if getTaskError != nil {
+				cp.queryTimeout.Count(getTaskError)
 				log.Error("failed to get unassigned chunk proving tasks", "height", getTaskParameter.ProverHeight, "err", getTaskError)
 				return nil, ErrCoordinatorInternalFailure
 			}
@@ -106,8 +128,11 @@ func (cp *ChunkProverTask) Assign(ctx *gin.Context, getTaskParameter *coordinato
 			return nil, nil
 		}
 
-		rowsAffected, updateAttemptsErr := cp.chunkOrm.UpdateChunkAttempts(ctx.Copy(), tmpChunkTask.Index, tmpChunkTask.ActiveAttempts, tmpChunkTask.TotalAttempts)
+		queryCtx, cancel = cp.queryTimeout.Context(ctx.Copy())
+		rowsAffected, updateAttemptsErr := cp.chunkOrm.UpdateChunkAttempts(queryCtx, tmpChunkTask.Index, tmpChunkTask.ActiveAttempts, tmpChunkTask.TotalAttempts)
+		cancel()
 		if updateAttemptsErr != nil {
+			cp.queryTimeout.Count(updateAttemptsErr)
 			log.Error("failed to update chunk attempts", "height", getTaskParameter.ProverHeight, "err", updateAttemptsErr)
 			return nil, ErrCoordinatorInternalFailure
 		}
@@ -140,19 +165,32 @@ func (cp *ChunkProverTask) Assign(ctx *gin.Context, getTaskParameter *coordinato
 		AssignedAt: utils.NowUTC(),
 	}
 
-	if err = cp.proverTaskOrm.InsertProverTask(ctx.Copy(), &proverTask); err != nil {
+	queryCtx, cancel := cp.queryTimeout.Context(ctx.Copy())
+	err = cp.proverTaskOrm.InsertProverTask(queryCtx, &proverTask)
+	cancel()
+	if err != nil {
+		cp.queryTimeout.Count(err)
 		cp.recoverActiveAttempts(ctx, chunkTask)
 		log.Error("insert chunk prover task fail", "taskID", chunkTask.Hash, "publicKey", taskCtx.PublicKey, "err", err)
 		return nil, ErrCoordinatorInternalFailure
 	}
 
-	taskMsg, err := cp.formatProverTask(ctx.Copy(), &proverTask)
+	queryCtx, cancel = cp.queryTimeout.Context(ctx.Copy())
+	taskMsg, err := cp.formatProverTask(queryCtx, &proverTask)
+	cancel()
 	if err != nil {
+		cp.queryTimeout.Count(err)
 		cp.recoverActiveAttempts(ctx, chunkTask)
 		log.Error("format prover task failure", "hash", chunkTask.Hash, "err", err)
 		return nil, ErrCoordinatorInternalFailure
 	}
 
+	// only the first assignment measures how long the task waited for a prover, prover_assigned_at is
+	// set by the first assignment and kept by the later ones
+	if chunkTask.ProverAssignedAt == nil {
+		cp.chunkAssignmentLatency.Observe(time.Since(chunkTask.CreatedAt).Seconds())
+	}
+
 	cp.chunkTaskGetTaskTotal.WithLabelValues(taskCtx.HardForkName).Inc()
 	cp.chunkTaskGetTaskProver.With(prometheus.Labels{
 		coordinatorType.LabelProverName:      proverTask.ProverName,
@@ -189,7 +227,10 @@ func (cp *ChunkProverTask) formatProverTask(ctx context.Context, task *orm.Prove
 }
 
 func (cp *ChunkProverTask) recoverActiveAttempts(ctx *gin.Context, chunkTask *orm.Chunk) {
-	if err := cp.chunkOrm.DecreaseActiveAttemptsByHash(ctx, chunkTask.Hash); err != nil {
+	queryCtx, cancel := cp.queryTimeout.Context(ctx.Copy())
+	defer cancel()
+	if err := cp.chunkOrm.DecreaseActiveAttemptsByHash(queryCtx, chunkTask.Hash); err != nil {
+		cp.queryTimeout.Count(err)
 		log.Error("failed to recover chunk active attempts", "hash", chunkTask.Hash, "error", err)
 	}
 }
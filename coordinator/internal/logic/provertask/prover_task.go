@@ -13,6 +13,7 @@ import (
 	"scroll-tech/common/version"
 
 	"scroll-tech/coordinator/internal/config"
+	"scroll-tech/coordinator/internal/logic/querytimeout"
 	"scroll-tech/coordinator/internal/orm"
 	coordinatorType "scroll-tech/coordinator/internal/types"
 )
@@ -43,6 +44,8 @@ type BaseProverTask struct {
 	blockOrm           *orm.L2Block
 	proverTaskOrm      *orm.ProverTask
 	proverBlockListOrm *orm.ProverBlockList
+
+	queryTimeout *querytimeout.QueryTimeout
 }
 
 type proverTaskContext struct {
@@ -100,16 +103,22 @@ func (b *BaseProverTask) checkParameter(ctx *gin.Context, getTaskParameter *coor
 		return nil, fmt.Errorf("incompatible vk. please check your params files or config files")
 	}
 
-	isBlocked, err := b.proverBlockListOrm.IsPublicKeyBlocked(ctx.Copy(), publicKey.(string))
+	queryCtx, cancel := b.queryTimeout.Context(ctx.Copy())
+	isBlocked, err := b.proverBlockListOrm.IsPublicKeyBlocked(queryCtx, publicKey.(string))
+	cancel()
 	if err != nil {
+		b.queryTimeout.Count(err)
 		return nil, fmt.Errorf("failed to check whether the public key %s is blocked before assigning a chunk task, err: %w, proverName: %s, proverVersion: %s", publicKey, err, proverName, proverVersion)
 	}
 	if isBlocked {
 		return nil, fmt.Errorf("public key %s is blocked from fetching tasks. ProverName: %s, ProverVersion: %s", publicKey, proverName, proverVersion)
 	}
 
-	isAssigned, err := b.proverTaskOrm.IsProverAssigned(ctx.Copy(), publicKey.(string))
+	queryCtx, cancel = b.queryTimeout.Context(ctx.Copy())
+	isAssigned, err := b.proverTaskOrm.IsProverAssigned(queryCtx, publicKey.(string))
+	cancel()
 	if err != nil {
+		b.queryTimeout.Count(err)
 		return nil, fmt.Errorf("failed to check if prover %s is assigned a task, err: %w", publicKey.(string), err)
 	}
 
@@ -137,6 +146,9 @@ func (b *BaseProverTask) getHardForkNumberByName(forkName string) (uint64, error
 var (
 	getTaskCounterInitOnce sync.Once
 	getTaskCounterVec      *prometheus.CounterVec = nil
+
+	assignmentLatencyInitOnce     sync.Once
+	assignmentLatencyHistogramVec *prometheus.HistogramVec = nil
 )
 
 func newGetTaskCounterVec(factory promauto.Factory, taskType string) *prometheus.CounterVec {
@@ -152,3 +164,15 @@ func newGetTaskCounterVec(factory promauto.Factory, taskType string) *prometheus
 
 	return getTaskCounterVec.MustCurryWith(prometheus.Labels{"task_type": taskType})
 }
+
+func newAssignmentLatencyHistogram(factory promauto.Factory, taskType string) prometheus.Observer {
+	assignmentLatencyInitOnce.Do(func() {
+		assignmentLatencyHistogramVec = factory.NewHistogramVec(prometheus.HistogramOpts{
+			Name:    "coordinator_block_assignment_latency_seconds",
+			Help:    "Time from chunk/batch creation to its first assignment to a prover.",
+			Buckets: []float64{1, 5, 10, 30, 60, 120, 300, 600, 1800, 3600},
+		}, []string{"task_type"})
+	})
+
+	return assignmentLatencyHistogramVec.WithLabelValues(taskType)
+}
@@ -0,0 +1,52 @@
+package querytimeout
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+var (
+	timeoutCounterInitOnce sync.Once
+	timeoutCounter         prometheus.Counter
+)
+
+// QueryTimeout bounds the database queries of the api logic by orm_query_timeout_ms.
+type QueryTimeout struct {
+	timeout      time.Duration
+	timeoutTotal prometheus.Counter
+}
+
+// NewQueryTimeout creates a QueryTimeout, a non-positive timeoutMs disables the timeout.
+func NewQueryTimeout(timeoutMs int, reg prometheus.Registerer) *QueryTimeout {
+	timeoutCounterInitOnce.Do(func() {
+		timeoutCounter = promauto.With(reg).NewCounter(prometheus.CounterOpts{
+			Name: "coordinator_orm_timeouts_total",
+			Help: "Total number of database queries of the api handlers reaching orm_query_timeout_ms.",
+		})
+	})
+
+	return &QueryTimeout{
+		timeout:      time.Duration(timeoutMs) * time.Millisecond,
+		timeoutTotal: timeoutCounter,
+	}
+}
+
+// Context returns the context for a single database query or transaction.
+func (q *QueryTimeout) Context(parent context.Context) (context.Context, context.CancelFunc) {
+	if q.timeout <= 0 {
+		return context.WithCancel(parent)
+	}
+	return context.WithTimeout(parent, q.timeout)
+}
+
+// Count counts the error if the query reached the timeout.
+func (q *QueryTimeout) Count(err error) {
+	if errors.Is(err, context.DeadlineExceeded) {
+		q.timeoutTotal.Inc()
+	}
+}
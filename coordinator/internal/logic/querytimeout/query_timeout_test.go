@@ -0,0 +1,35 @@
+package querytimeout
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestQueryTimeout(t *testing.T) {
+	t.Run("disabled", func(t *testing.T) {
+		q := NewQueryTimeout(0, prometheus.NewRegistry())
+		ctx, cancel := q.Context(context.Background())
+		defer cancel()
+		_, hasDeadline := ctx.Deadline()
+		assert.False(t, hasDeadline)
+	})
+
+	t.Run("timeout", func(t *testing.T) {
+		q := NewQueryTimeout(1, prometheus.NewRegistry())
+		ctx, cancel := q.Context(context.Background())
+		defer cancel()
+		<-ctx.Done()
+		assert.ErrorIs(t, ctx.Err(), context.DeadlineExceeded)
+
+		before := testutil.ToFloat64(q.timeoutTotal)
+		q.Count(nil)
+		q.Count(context.Canceled)
+		q.Count(fmt.Errorf("Chunk.GetChunkByHash error: %w", ctx.Err()))
+		assert.Equal(t, before+1, testutil.ToFloat64(q.timeoutTotal))
+	})
+}
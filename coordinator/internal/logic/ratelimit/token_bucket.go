@@ -0,0 +1,61 @@
+package ratelimit
+
+import (
+	"math"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"golang.org/x/time/rate"
+)
+
+// TokenBucket limits the rate of task assignments handed out to provers.
+type TokenBucket struct {
+	limiter *rate.Limiter
+}
+
+// NewTokenBucket creates a token bucket refilled with maxPerSecond tokens per second.
+// A non-positive maxPerSecond disables the limit.
+func NewTokenBucket(maxPerSecond float64, reg prometheus.Registerer) *TokenBucket {
+	limit := rate.Inf
+	burst := 1
+	if maxPerSecond > 0 {
+		limit = rate.Limit(maxPerSecond)
+		burst = int(math.Ceil(maxPerSecond))
+	}
+
+	tb := &TokenBucket{
+		limiter: rate.NewLimiter(limit, burst),
+	}
+
+	promauto.With(reg).NewGaugeFunc(prometheus.GaugeOpts{
+		Name: "coordinator_assignment_rate_tokens_remaining",
+		Help: "The number of task assignments currently available in the assignment rate limiter.",
+	}, tb.limiter.Tokens)
+
+	return tb
+}
+
+// Reservation is a token taken for an assignment.
+type Reservation struct {
+	reservation *rate.Reservation
+	reservedAt  time.Time
+}
+
+// Cancel gives the token back when no task has been assigned with it.
+func (r *Reservation) Cancel() {
+	// the limiter only restores the tokens of a reservation cancelled before its time to act
+	r.reservation.CancelAt(r.reservedAt)
+}
+
+// Reserve takes a token for a new assignment before the task is assigned, so concurrent assignments
+// can't exceed the limit. It returns nil when no token is available.
+func (tb *TokenBucket) Reserve() *Reservation {
+	now := time.Now()
+	reservation := tb.limiter.ReserveN(now, 1)
+	if !reservation.OK() || reservation.DelayFrom(now) > 0 {
+		reservation.CancelAt(now)
+		return nil
+	}
+	return &Reservation{reservation: reservation, reservedAt: now}
+}
@@ -0,0 +1,32 @@
+package ratelimit
+
+import (
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTokenBucket(t *testing.T) {
+	t.Run("unlimited", func(t *testing.T) {
+		tb := NewTokenBucket(0, prometheus.NewRegistry())
+		for i := 0; i < 100; i++ {
+			assert.NotNil(t, tb.Reserve())
+		}
+	})
+
+	t.Run("limited", func(t *testing.T) {
+		tb := NewTokenBucket(0.001, prometheus.NewRegistry())
+		assert.NotNil(t, tb.Reserve())
+		assert.Nil(t, tb.Reserve())
+	})
+
+	t.Run("cancel", func(t *testing.T) {
+		tb := NewTokenBucket(0.001, prometheus.NewRegistry())
+		reservation := tb.Reserve()
+		assert.NotNil(t, reservation)
+		reservation.Cancel()
+		assert.NotNil(t, tb.Reserve())
+		assert.Nil(t, tb.Reserve())
+	})
+}
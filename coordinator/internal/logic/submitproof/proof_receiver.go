@@ -18,6 +18,7 @@ import (
 	"scroll-tech/common/types/message"
 
 	"scroll-tech/coordinator/internal/config"
+	"scroll-tech/coordinator/internal/logic/querytimeout"
 	"scroll-tech/coordinator/internal/logic/verifier"
 	"scroll-tech/coordinator/internal/orm"
 	coordinatorType "scroll-tech/coordinator/internal/types"
@@ -28,6 +29,8 @@ var (
 	ErrValidatorFailureProofMsgStatusNotOk = errors.New("validator failure proof msg status not ok")
 	// ErrValidatorFailureProverTaskEmpty get none prover task
 	ErrValidatorFailureProverTaskEmpty = errors.New("validator failure get none prover task for the proof")
+	// ErrValidatorFailureProverTaskMismatch the submitted proof doesn't belong to the prover task
+	ErrValidatorFailureProverTaskMismatch = errors.New("validator failure proof task id or type mismatch with prover task")
 	// ErrValidatorFailureProverTaskCannotSubmitTwice prove task can not submit proof twice
 	ErrValidatorFailureProverTaskCannotSubmitTwice = errors.New("validator failure prove task cannot submit proof twice")
 	// ErrValidatorFailureProofTimeout the submit proof is timeout
@@ -51,14 +54,18 @@ type ProofReceiverLogic struct {
 	db  *gorm.DB
 	cfg *config.ProverManager
 
-	verifier *verifier.Verifier
+	verifier     *verifier.Verifier
+	queryTimeout *querytimeout.QueryTimeout
 
 	proofReceivedTotal                    prometheus.Counter
 	proofSubmitFailure                    prometheus.Counter
 	verifierTotal                         *prometheus.CounterVec
 	verifierFailureTotal                  *prometheus.CounterVec
 	proverTaskProveDuration               prometheus.Histogram
+	proofSizeBytes                        *prometheus.HistogramVec
+	suspiciousProofTotal                  *prometheus.CounterVec
 	validateFailureTotal                  prometheus.Counter
+	validateFailureProverTaskMismatch     prometheus.Counter
 	validateFailureProverTaskSubmitTwice  prometheus.Counter
 	validateFailureProverTaskStatusNotOk  prometheus.Counter
 	validateFailureProverTaskTimeout      prometheus.Counter
@@ -75,7 +82,8 @@ func NewSubmitProofReceiverLogic(cfg *config.ProverManager, db *gorm.DB, vf *ver
 		cfg: cfg,
 		db:  db,
 
-		verifier: vf,
+		verifier:     vf,
+		queryTimeout: querytimeout.NewQueryTimeout(cfg.OrmQueryTimeoutMs, reg),
 
 		proofReceivedTotal: promauto.With(reg).NewCounter(prometheus.CounterOpts{
 			Name: "coordinator_submit_proof_total",
@@ -98,10 +106,23 @@ func NewSubmitProofReceiverLogic(cfg *config.ProverManager, db *gorm.DB, vf *ver
 			Help:    "Time spend by prover prove task.",
 			Buckets: []float64{180, 300, 480, 600, 900, 1200, 1800},
 		}),
+		proofSizeBytes: promauto.With(reg).NewHistogramVec(prometheus.HistogramOpts{
+			Name:    "coordinator_submit_proof_size_bytes",
+			Help:    "Size of the valid proofs stored by coordinator.",
+			Buckets: prometheus.ExponentialBuckets(1024, 2, 12),
+		}, []string{"task_type"}),
+		suspiciousProofTotal: promauto.With(reg).NewCounterVec(prometheus.CounterOpts{
+			Name: "coordinator_submit_proof_suspicious_total",
+			Help: "Total number of proofs submitted faster than the min plausible proof time.",
+		}, []string{coordinatorType.LabelProverName, coordinatorType.LabelProverPublicKey, coordinatorType.LabelProverVersion}),
 		validateFailureTotal: promauto.With(reg).NewCounter(prometheus.CounterOpts{
 			Name: "coordinator_validate_failure_total",
 			Help: "Total number of submit proof validate failure.",
 		}),
+		validateFailureProverTaskMismatch: promauto.With(reg).NewCounter(prometheus.CounterOpts{
+			Name: "coordinator_validate_failure_task_mismatch_total",
+			Help: "Total number of submit proof validate failure task id or type mismatch.",
+		}),
 		validateFailureProverTaskSubmitTwice: promauto.With(reg).NewCounter(prometheus.CounterOpts{
 			Name: "coordinator_validate_failure_submit_twice_total",
 			Help: "Total number of submit proof validate failure submit twice.",
@@ -138,15 +159,20 @@ func (m *ProofReceiverLogic) HandleZkProof(ctx *gin.Context, proofMsg *message.P
 
 	var proverTask *orm.ProverTask
 	var err error
+	queryCtx, cancel := m.queryTimeout.Context(ctx.Copy())
 	if proofParameter.UUID != "" {
-		proverTask, err = m.proverTaskOrm.GetProverTaskByUUIDAndPublicKey(ctx.Copy(), proofParameter.UUID, pk)
+		proverTask, err = m.proverTaskOrm.GetProverTaskByUUIDAndPublicKey(queryCtx, proofParameter.UUID, pk)
+		cancel()
+		m.queryTimeout.Count(err)
 		if proverTask == nil || err != nil {
 			log.Error("get none prover task for the proof", "uuid", proofParameter.UUID, "key", pk, "taskID", proofMsg.ID, "error", err)
 			return ErrValidatorFailureProverTaskEmpty
 		}
 	} else {
 		// TODO When prover all have upgrade, need delete this logic
-		proverTask, err = m.proverTaskOrm.GetAssignedProverTaskByTaskIDAndProver(ctx.Copy(), proofMsg.Type, proofMsg.ID, pk, pv)
+		proverTask, err = m.proverTaskOrm.GetAssignedProverTaskByTaskIDAndProver(queryCtx, proofMsg.Type, proofMsg.ID, pk, pv)
+		cancel()
+		m.queryTimeout.Count(err)
 		if proverTask == nil || err != nil {
 			log.Error("get none prover task for the proof", "key", pk, "taskID", proofMsg.ID, "error", err)
 			return ErrValidatorFailureProverTaskEmpty
@@ -163,6 +189,16 @@ func (m *ProofReceiverLogic) HandleZkProof(ctx *gin.Context, proofMsg *message.P
 		return err
 	}
 
+	if m.cfg.MinPlausibleProofTimeSec > 0 && proofTime < time.Duration(m.cfg.MinPlausibleProofTimeSec)*time.Second {
+		m.suspiciousProofTotal.With(prometheus.Labels{
+			coordinatorType.LabelProverName:      proverTask.ProverName,
+			coordinatorType.LabelProverPublicKey: proverTask.ProverPublicKey,
+			coordinatorType.LabelProverVersion:   proverTask.ProverVersion,
+		}).Inc()
+		log.Warn("proof submitted faster than the min plausible proof time", "proofID", proofMsg.ID, "proverName", proverTask.ProverName,
+			"proverPublicKey", pk, "proveType", proverTask.TaskType, "proofTime", proofTime, "minPlausibleProofTimeSec", m.cfg.MinPlausibleProofTimeSec)
+	}
+
 	m.verifierTotal.WithLabelValues(pv).Inc()
 
 	success := true
@@ -202,7 +238,27 @@ func (m *ProofReceiverLogic) HandleZkProof(ctx *gin.Context, proofMsg *message.P
 	return nil
 }
 
-func (m *ProofReceiverLogic) checkAreAllChunkProofsReady(ctx context.Context, chunkHash string) error {
+// observeProofSize records the size of the proof and instances bytes of a stored proof.
+func (m *ProofReceiverLogic) observeProofSize(proofMsg *message.ProofMsg) {
+	switch proofMsg.Type {
+	case message.ProofTypeChunk:
+		if proofMsg.ChunkProof != nil {
+			m.proofSizeBytes.WithLabelValues("chunk").Observe(float64(len(proofMsg.ChunkProof.Proof) + len(proofMsg.ChunkProof.Instances)))
+		}
+	case message.ProofTypeBatch:
+		if proofMsg.BatchProof != nil {
+			m.proofSizeBytes.WithLabelValues("batch").Observe(float64(len(proofMsg.BatchProof.Proof) + len(proofMsg.BatchProof.Instances)))
+		}
+	}
+}
+
+func (m *ProofReceiverLogic) checkAreAllChunkProofsReady(ctx context.Context, chunkHash string) (err error) {
+	ctx, cancel := m.queryTimeout.Context(ctx)
+	defer func() {
+		cancel()
+		m.queryTimeout.Count(err)
+	}()
+
 	batch, err := m.chunkOrm.GetChunkByHash(ctx, chunkHash)
 	if err != nil {
 		return err
@@ -228,6 +284,21 @@ func (m *ProofReceiverLogic) validator(ctx context.Context, proverTask *orm.Prov
 		}
 	}()
 
+	// Ensure the proof is for the task assigned under this uuid, otherwise a prover could
+	// store a proof for another chunk/batch by using the uuid of its own prover task.
+	if proverTask.TaskID != proofMsg.ID || message.ProofType(proverTask.TaskType) != proofMsg.Type {
+		m.validateFailureProverTaskMismatch.Inc()
+		log.Error("proof task mismatch with prover task",
+			"uuid", proverTask.UUID, "proverTaskID", proverTask.TaskID, "proofTaskID", proofMsg.ID,
+			"proverTaskType", message.ProofType(proverTask.TaskType).String(), "proofTaskType", proofMsg.Type.String(),
+			"proverName", proverTask.ProverName, "proverPublicKey", pk, "forkName", forkName)
+		// reject the prover task as well, unless it has already been closed by an earlier submission
+		if types.ProverProveStatus(proverTask.ProvingStatus) == types.ProverAssigned {
+			m.proofRecover(ctx, proverTask, types.ProverTaskFailureTypeVerifiedFailed, proofMsg)
+		}
+		return ErrValidatorFailureProverTaskMismatch
+	}
+
 	// Ensure this prover is eligible to participate in the prover task.
 	if types.ProverProveStatus(proverTask.ProvingStatus) == types.ProverProofValid ||
 		types.ProverProveStatus(proverTask.ProvingStatus) == types.ProverProofInvalid {
@@ -272,9 +343,11 @@ func (m *ProofReceiverLogic) validator(ctx context.Context, proverTask *orm.Prov
 	}
 
 	// store the proof to prover task
-	if updateTaskProofErr := m.updateProverTaskProof(ctx, proverTask, proofMsg); updateTaskProofErr != nil {
-		log.Warn("update prover task proof failure", "hash", proofMsg.ID, "proverPublicKey", pk, "forkName", forkName,
-			"taskType", proverTask.TaskType, "proverName", proverTask.ProverName, "error", updateTaskProofErr)
+	if !m.cfg.VerifyOnly {
+		if updateTaskProofErr := m.updateProverTaskProof(ctx, proverTask, proofMsg); updateTaskProofErr != nil {
+			log.Warn("update prover task proof failure", "hash", proofMsg.ID, "proverPublicKey", pk, "forkName", forkName,
+				"taskType", proverTask.TaskType, "proverName", proverTask.ProverName, "error", updateTaskProofErr)
+		}
 	}
 
 	// if the batch/chunk have proved and verifier success, need skip this submit proof
@@ -310,13 +383,19 @@ func (m *ProofReceiverLogic) closeProofTask(ctx context.Context, proverTask *orm
 // UpdateProofStatus update the chunk/batch task and session info status
 func (m *ProofReceiverLogic) updateProofStatus(ctx context.Context, proverTask *orm.ProverTask,
 	proofMsg *message.ProofMsg, status types.ProverProveStatus, failureType types.ProverTaskFailureType, proofTimeSec uint64) error {
-	err := m.db.Transaction(func(tx *gorm.DB) error {
+	// the attempts belong to the task of the prover task, which may differ from the proof msg of a rejected submission
+	taskType := message.ProofType(proverTask.TaskType)
+	var proofStored bool
+	// the whole transaction is bounded by one query timeout
+	txCtx, cancel := m.queryTimeout.Context(ctx)
+	err := m.db.WithContext(txCtx).Transaction(func(tx *gorm.DB) error {
+		ctx := txCtx
 		if updateErr := m.proverTaskOrm.UpdateProverTaskProvingStatusAndFailureType(ctx, proverTask.UUID, status, failureType, tx); updateErr != nil {
 			log.Error("failed to update prover task proving status and failure type", "uuid", proverTask.UUID, "error", updateErr)
 			return updateErr
 		}
 
-		switch proofMsg.Type {
+		switch taskType {
 		case message.ProofTypeChunk:
 			if err := m.chunkOrm.DecreaseActiveAttemptsByHash(ctx, proverTask.TaskID, tx); err != nil {
 				log.Error("failed to update chunk proving_status as failed", "hash", proverTask.TaskID, "error", err)
@@ -330,11 +409,29 @@ func (m *ProofReceiverLogic) updateProofStatus(ctx context.Context, proverTask *
 		}
 
 		// if the block batch has proof verified, so the failed status not update block batch proving status
-		if m.checkIsTaskSuccess(ctx, proverTask.TaskID, proofMsg.Type) {
+		if m.checkIsTaskSuccess(ctx, proverTask.TaskID, taskType) {
 			log.Info("update proof status skip because this chunk/batch has been verified", "hash", proverTask.TaskID, "public key", proverTask.ProverPublicKey)
 			return nil
 		}
 
+		if status == types.ProverProofValid && m.cfg.VerifyOnly {
+			// the attempt stays consumed, so the chunk/batch is only proved session_attempts times and then
+			// ends failed instead of being handed out again forever.
+			var failErr error
+			switch proofMsg.Type {
+			case message.ProofTypeChunk:
+				failErr = m.chunkOrm.UpdateExhaustedProvingStatusFailedByHash(ctx, proverTask.TaskID, m.cfg.SessionAttempts, tx)
+			case message.ProofTypeBatch:
+				failErr = m.batchOrm.UpdateExhaustedProvingStatusFailedByHash(ctx, proverTask.TaskID, m.cfg.SessionAttempts, tx)
+			}
+			if failErr != nil {
+				log.Error("failed to update exhausted chunk/batch proving_status as failed", "hash", proverTask.TaskID, "public key", proverTask.ProverPublicKey, "error", failErr)
+				return failErr
+			}
+			log.Info("proof valid but not stored", "hash", proverTask.TaskID, "public key", proverTask.ProverPublicKey, "taskType", proofMsg.Type.String())
+			return nil
+		}
+
 		if status == types.ProverProofValid {
 			var storeProofErr error
 			switch proofMsg.Type {
@@ -347,15 +444,22 @@ func (m *ProofReceiverLogic) updateProofStatus(ctx context.Context, proverTask *
 				log.Error("failed to store chunk/batch proof and proving status", "hash", proverTask.TaskID, "public key", proverTask.ProverPublicKey, "error", storeProofErr)
 				return storeProofErr
 			}
+			proofStored = true
 		}
 		return nil
 	})
+	cancel()
 
 	if err != nil {
+		m.queryTimeout.Count(err)
 		return err
 	}
 
-	if status == types.ProverProofValid && proofMsg.Type == message.ProofTypeChunk {
+	if proofStored {
+		m.observeProofSize(proofMsg)
+	}
+
+	if status == types.ProverProofValid && proofMsg.Type == message.ProofTypeChunk && !m.cfg.VerifyOnly {
 		if checkReadyErr := m.checkAreAllChunkProofsReady(ctx, proverTask.TaskID); checkReadyErr != nil {
 			log.Error("failed to check are all chunk proofs ready", "error", checkReadyErr)
 			return checkReadyErr
@@ -369,6 +473,12 @@ func (m *ProofReceiverLogic) checkIsTaskSuccess(ctx context.Context, hash string
 	var provingStatus types.ProvingStatus
 	var err error
 
+	ctx, cancel := m.queryTimeout.Context(ctx)
+	defer func() {
+		cancel()
+		m.queryTimeout.Count(err)
+	}()
+
 	switch proofType {
 	case message.ProofTypeChunk:
 		provingStatus, err = m.chunkOrm.GetProvingStatusByHash(ctx, hash)
@@ -399,5 +509,10 @@ func (m *ProofReceiverLogic) updateProverTaskProof(ctx context.Context, proverTa
 	if len(proofBytes) == 0 || marshalErr != nil {
 		return fmt.Errorf("updateProverTaskProof marshal proof error:%w", marshalErr)
 	}
-	return m.proverTaskOrm.UpdateProverTaskProof(ctx, proverTask.UUID, proofBytes)
+
+	ctx, cancel := m.queryTimeout.Context(ctx)
+	defer cancel()
+	err := m.proverTaskOrm.UpdateProverTaskProof(ctx, proverTask.UUID, proofBytes)
+	m.queryTimeout.Count(err)
+	return err
 }
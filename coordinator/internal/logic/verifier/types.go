@@ -4,8 +4,8 @@ import (
 	"scroll-tech/coordinator/internal/config"
 )
 
-// InvalidTestProof invalid proof used in tests
-const InvalidTestProof = "this is a invalid proof"
+// InvalidTestProof invalid proof used in tests, its length passes the batch proof sanity check
+const InvalidTestProof = "this is a invalid proof for test"
 
 // Verifier represents a rust ffi to a halo2 verifier.
 type Verifier struct {
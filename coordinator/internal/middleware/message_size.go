@@ -0,0 +1,46 @@
+package middleware
+
+import (
+	"io"
+	"path"
+
+	"github.com/gin-gonic/gin"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+// countingReadCloser counts the bytes read from the wrapped request body.
+type countingReadCloser struct {
+	io.ReadCloser
+	n int64
+}
+
+func (c *countingReadCloser) Read(p []byte) (int, error) {
+	n, err := c.ReadCloser.Read(p)
+	c.n += int64(n)
+	return n, err
+}
+
+// MessageSizeMiddleware records the body size of the incoming requests, labelled by api name.
+// The Content-Length is used when present, otherwise (e.g. chunked encoding) the size is
+// the number of bytes the handlers read from the body.
+func MessageSizeMiddleware(reg prometheus.Registerer) gin.HandlerFunc {
+	messageSize := promauto.With(reg).NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "coordinator_message_size_bytes",
+		Help:    "The size of the request messages received by the coordinator.",
+		Buckets: []float64{1 << 10, 10 << 10, 100 << 10, 1 << 20, 10 << 20, 100 << 20},
+	}, []string{"message_type"})
+
+	return func(ctx *gin.Context) {
+		body := &countingReadCloser{ReadCloser: ctx.Request.Body}
+		ctx.Request.Body = body
+
+		ctx.Next()
+
+		size := ctx.Request.ContentLength
+		if size < 0 {
+			size = body.n
+		}
+		messageSize.WithLabelValues(path.Base(ctx.FullPath())).Observe(float64(size))
+	}
+}
@@ -344,6 +344,7 @@ func (o *Batch) UpdateProofAndProvingStatusByHash(ctx context.Context, hash stri
 }
 
 // UpdateBatchAttempts atomically increments the attempts count for the earliest available batch that meets the conditions.
+// The first assignment also records prover_assigned_at.
 func (o *Batch) UpdateBatchAttempts(ctx context.Context, index uint64, curActiveAttempts, curTotalAttempts int16) (int64, error) {
 	db := o.db.WithContext(ctx)
 	db = db.Model(&Batch{})
@@ -354,6 +355,8 @@ func (o *Batch) UpdateBatchAttempts(ctx context.Context, index uint64, curActive
 		"proving_status":  types.ProvingTaskAssigned,
 		"total_attempts":  gorm.Expr("total_attempts + 1"),
 		"active_attempts": gorm.Expr("active_attempts + 1"),
+		// here why need use UTC time. see scroll/common/databased/db.go
+		"prover_assigned_at": gorm.Expr("COALESCE(prover_assigned_at, ?)", utils.NowUTC()),
 	})
 
 	if result.Error != nil {
@@ -382,3 +385,22 @@ func (o *Batch) DecreaseActiveAttemptsByHash(ctx context.Context, batchHash stri
 	}
 	return nil
 }
+
+// UpdateExhaustedProvingStatusFailedByHash sets an assigned batch to failed once its attempts are used up
+// and none of them is still active, e.g. when its proofs are verified but not stored.
+func (o *Batch) UpdateExhaustedProvingStatusFailedByHash(ctx context.Context, batchHash string, maxAttempts uint8, dbTX ...*gorm.DB) error {
+	db := o.db
+	if len(dbTX) > 0 && dbTX[0] != nil {
+		db = dbTX[0]
+	}
+	db = db.WithContext(ctx)
+	db = db.Model(&Batch{})
+	db = db.Where("hash = ?", batchHash)
+	db = db.Where("proving_status = ?", int(types.ProvingTaskAssigned))
+	db = db.Where("total_attempts >= ?", maxAttempts)
+	db = db.Where("active_attempts = ?", 0)
+	if err := db.Update("proving_status", int(types.ProvingTaskFailed)).Error; err != nil {
+		return fmt.Errorf("Batch.UpdateExhaustedProvingStatusFailedByHash error: %w, batch hash: %v", err, batchHash)
+	}
+	return nil
+}
@@ -219,6 +219,42 @@ func (o *Chunk) GetChunkByStartBlockNumber(ctx context.Context, startBlockNumber
 	return &chunk, nil
 }
 
+// GetFirstChunkStartingFrom retrieves the first chunk whose start block number is not less than the given block number.
+// Chunks cover consecutive blocks, so ordering by start_block_number follows the chunk index and uses its index.
+func (o *Chunk) GetFirstChunkStartingFrom(ctx context.Context, blockNumber uint64) (*Chunk, error) {
+	db := o.db.WithContext(ctx)
+	db = db.Model(&Chunk{})
+	db = db.Where("start_block_number >= ?", blockNumber)
+	db = db.Order("start_block_number ASC")
+
+	var chunk Chunk
+	if err := db.First(&chunk).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("Chunk.GetFirstChunkStartingFrom error: %w, block number: %v", err, blockNumber)
+	}
+	return &chunk, nil
+}
+
+// GetFirstChunkEndingAfter retrieves the first chunk whose end block number is greater than the given block number.
+// Chunks cover consecutive blocks, so ordering by end_block_number follows the chunk index and uses its index.
+func (o *Chunk) GetFirstChunkEndingAfter(ctx context.Context, blockNumber uint64) (*Chunk, error) {
+	db := o.db.WithContext(ctx)
+	db = db.Model(&Chunk{})
+	db = db.Where("end_block_number > ?", blockNumber)
+	db = db.Order("end_block_number ASC")
+
+	var chunk Chunk
+	if err := db.First(&chunk).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("Chunk.GetFirstChunkEndingAfter error: %w, block number: %v", err, blockNumber)
+	}
+	return &chunk, nil
+}
+
 // GetAttemptsByHash get chunk attempts by hash. Used by unit test
 func (o *Chunk) GetAttemptsByHash(ctx context.Context, hash string) (int16, int16, error) {
 	db := o.db.WithContext(ctx)
@@ -381,6 +417,7 @@ func (o *Chunk) UpdateBatchHashInRange(ctx context.Context, startIndex uint64, e
 }
 
 // UpdateChunkAttempts atomically increments the attempts count for the earliest available chunk that meets the conditions.
+// The first assignment also records prover_assigned_at.
 func (o *Chunk) UpdateChunkAttempts(ctx context.Context, index uint64, curActiveAttempts, curTotalAttempts int16) (int64, error) {
 	db := o.db.WithContext(ctx)
 	db = db.Model(&Chunk{})
@@ -391,6 +428,8 @@ func (o *Chunk) UpdateChunkAttempts(ctx context.Context, index uint64, curActive
 		"proving_status":  types.ProvingTaskAssigned,
 		"total_attempts":  gorm.Expr("total_attempts + 1"),
 		"active_attempts": gorm.Expr("active_attempts + 1"),
+		// here why need use UTC time. see scroll/common/databased/db.go
+		"prover_assigned_at": gorm.Expr("COALESCE(prover_assigned_at, ?)", utils.NowUTC()),
 	})
 
 	if result.Error != nil {
@@ -419,3 +458,22 @@ func (o *Chunk) DecreaseActiveAttemptsByHash(ctx context.Context, chunkHash stri
 	}
 	return nil
 }
+
+// UpdateExhaustedProvingStatusFailedByHash sets an assigned chunk to failed once its attempts are used up
+// and none of them is still active, e.g. when its proofs are verified but not stored.
+func (o *Chunk) UpdateExhaustedProvingStatusFailedByHash(ctx context.Context, chunkHash string, maxAttempts uint8, dbTX ...*gorm.DB) error {
+	db := o.db
+	if len(dbTX) > 0 && dbTX[0] != nil {
+		db = dbTX[0]
+	}
+	db = db.WithContext(ctx)
+	db = db.Model(&Chunk{})
+	db = db.Where("hash = ?", chunkHash)
+	db = db.Where("proving_status = ?", int(types.ProvingTaskAssigned))
+	db = db.Where("total_attempts >= ?", maxAttempts)
+	db = db.Where("active_attempts = ?", 0)
+	if err := db.Update("proving_status", int(types.ProvingTaskFailed)).Error; err != nil {
+		return fmt.Errorf("Chunk.UpdateExhaustedProvingStatusFailedByHash error: %w, chunk hash: %v", err, chunkHash)
+	}
+	return nil
+}
@@ -19,11 +19,12 @@ func Route(router *gin.Engine, cfg *config.Config, reg prometheus.Registerer) {
 
 	r := router.Group("coordinator")
 
-	v1(r, cfg)
+	v1(r, cfg, reg)
 }
 
-func v1(router *gin.RouterGroup, conf *config.Config) {
+func v1(router *gin.RouterGroup, conf *config.Config, reg prometheus.Registerer) {
 	r := router.Group("/v1")
+	r.Use(middleware.MessageSizeMiddleware(reg))
 
 	challengeMiddleware := middleware.ChallengeMiddleware(conf)
 	r.GET("/challenge", challengeMiddleware.LoginHandler)
@@ -14,6 +14,7 @@ import (
 	"time"
 
 	"github.com/gin-gonic/gin"
+	"github.com/prometheus/client_golang/prometheus"
 	"github.com/scroll-tech/da-codec/encoding"
 	"github.com/scroll-tech/go-ethereum/log"
 	"github.com/scroll-tech/go-ethereum/params"
@@ -41,7 +42,8 @@ const (
 )
 
 var (
-	conf *config.Config
+	conf       *config.Config
+	metricsReg *prometheus.Registry
 
 	testApps *testcontainers.TestcontainerApps
 
@@ -132,8 +134,9 @@ func setupCoordinator(t *testing.T, proversPerSession uint8, coordinatorURL stri
 	proofCollector := cron.NewCollector(context.Background(), db, conf, nil)
 
 	router := gin.New()
-	api.InitController(conf, &chainConf, db, nil)
-	route.Route(router, conf, nil)
+	metricsReg = prometheus.NewRegistry()
+	api.InitController(conf, &chainConf, db, metricsReg)
+	route.Route(router, conf, metricsReg)
 	srv := &http.Server{
 		Addr:    coordinatorURL,
 		Handler: router,
@@ -205,10 +208,14 @@ func TestApis(t *testing.T) {
 	t.Run("TestGetTaskBlocked", testGetTaskBlocked)
 	t.Run("TestOutdatedProverVersion", testOutdatedProverVersion)
 	t.Run("TestValidProof", testValidProof)
+	t.Run("TestVerifyOnlyProof", testVerifyOnlyProof)
+	t.Run("TestSuspiciousProof", testSuspiciousProof)
 	t.Run("TestInvalidProof", testInvalidProof)
+	t.Run("TestProofTaskMismatch", testProofTaskMismatch)
 	t.Run("TestProofGeneratedFailed", testProofGeneratedFailed)
 	t.Run("TestTimeoutProof", testTimeoutProof)
 	t.Run("TestHardFork", testHardForkAssignTask)
+	t.Run("TestBlockNumberScope", testBlockNumberScopeAssignTask)
 }
 
 func testHandshake(t *testing.T) {
@@ -544,6 +551,100 @@ func testHardForkAssignTask(t *testing.T) {
 	}
 }
 
+func testBlockNumberScopeAssignTask(t *testing.T) {
+	// the insert block number is 2 and 3
+	// chunk1 batch1 contains block number 2
+	// chunk2 batch2 contains block number 3
+	tests := []struct {
+		name              string
+		proofType         message.ProofType
+		minBlockNumber    uint64
+		maxBlockNumber    uint64
+		exceptTaskIndexes []int
+	}{
+		{name: "chunkNoLimit", proofType: message.ProofTypeChunk, exceptTaskIndexes: []int{0, 1}},
+		{name: "batchNoLimit", proofType: message.ProofTypeBatch, exceptTaskIndexes: []int{0, 1}},
+		{name: "chunkMinBlockNumber", proofType: message.ProofTypeChunk, minBlockNumber: 3, exceptTaskIndexes: []int{1}},
+		{name: "batchMinBlockNumber", proofType: message.ProofTypeBatch, minBlockNumber: 3, exceptTaskIndexes: []int{1}},
+		{name: "chunkMaxBlockNumber", proofType: message.ProofTypeChunk, maxBlockNumber: 2, exceptTaskIndexes: []int{0}},
+		{name: "batchMaxBlockNumber", proofType: message.ProofTypeBatch, maxBlockNumber: 2, exceptTaskIndexes: []int{0}},
+		{name: "chunkMinEqualMaxBlockNumber", proofType: message.ProofTypeChunk, minBlockNumber: 3, maxBlockNumber: 3, exceptTaskIndexes: []int{1}},
+		{name: "batchMinEqualMaxBlockNumber", proofType: message.ProofTypeBatch, minBlockNumber: 3, maxBlockNumber: 3, exceptTaskIndexes: []int{1}},
+		{name: "chunkMinBlockNumberAfterAll", proofType: message.ProofTypeChunk, minBlockNumber: 4},
+		{name: "batchMinBlockNumberAfterAll", proofType: message.ProofTypeBatch, minBlockNumber: 4},
+		{name: "chunkMaxBlockNumberBeforeAll", proofType: message.ProofTypeChunk, maxBlockNumber: 1},
+		{name: "batchMaxBlockNumberBeforeAll", proofType: message.ProofTypeBatch, maxBlockNumber: 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			coordinatorURL := randomURL()
+			collector, httpHandler := setupCoordinator(t, 3, coordinatorURL, map[string]int64{"istanbul": forkNumberTwo})
+			defer func() {
+				collector.Stop()
+				assert.NoError(t, httpHandler.Shutdown(context.Background()))
+			}()
+			conf.ProverManager.MinBlockNumber = tt.minBlockNumber
+			conf.ProverManager.MaxBlockNumber = tt.maxBlockNumber
+
+			chunkProof := &message.ChunkProof{
+				StorageTrace: []byte("testStorageTrace"),
+				Protocol:     []byte("testProtocol"),
+				Proof:        []byte("testProof"),
+				Instances:    []byte("testInstance"),
+				Vk:           []byte("testVk"),
+				ChunkInfo:    nil,
+			}
+
+			err := l2BlockOrm.InsertL2Blocks(context.Background(), []*encoding.Block{block1, block2})
+			assert.NoError(t, err)
+
+			var chunkHashes, batchHashes []string
+			for i, c := range []*encoding.Chunk{hardForkChunk1, hardForkChunk2} {
+				dbChunk, err := chunkOrm.InsertChunk(context.Background(), c)
+				assert.NoError(t, err)
+				blockNumber := c.Blocks[0].Header.Number.Uint64()
+				err = l2BlockOrm.UpdateChunkHashInRange(context.Background(), blockNumber, blockNumber, dbChunk.Hash)
+				assert.NoError(t, err)
+				err = chunkOrm.UpdateProofAndProvingStatusByHash(context.Background(), dbChunk.Hash, chunkProof, types.ProvingTaskUnassigned, 1)
+				assert.NoError(t, err)
+				chunkHashes = append(chunkHashes, dbChunk.Hash)
+
+				dbBatch, err := batchOrm.InsertBatch(context.Background(), []*encoding.Batch{hardForkBatch1, hardForkBatch2}[i])
+				assert.NoError(t, err)
+				err = chunkOrm.UpdateBatchHashInRange(context.Background(), uint64(i), uint64(i), dbBatch.Hash)
+				assert.NoError(t, err)
+				err = batchOrm.UpdateChunkProofsStatusByBatchHash(context.Background(), dbBatch.Hash, types.ChunkProofsStatusReady)
+				assert.NoError(t, err)
+				batchHashes = append(batchHashes, dbBatch.Hash)
+			}
+
+			taskHashes := chunkHashes
+			if tt.proofType == message.ProofTypeBatch {
+				taskHashes = batchHashes
+			}
+			var exceptTaskIDs []string
+			for _, index := range tt.exceptTaskIndexes {
+				exceptTaskIDs = append(exceptTaskIDs, taskHashes[index])
+			}
+
+			var taskIDs []string
+			for i := 0; i < 2; i++ {
+				mockProver := newMockProver(t, fmt.Sprintf("mock_prover_%d", i), coordinatorURL, tt.proofType, version.Version)
+				proverTask, errCode, errMsg := mockProver.getProverTask(t, tt.proofType, "istanbul")
+				if i >= len(exceptTaskIDs) {
+					assert.Equal(t, types.ErrCoordinatorEmptyProofData, errCode)
+					assert.Equal(t, "get empty prover task", errMsg)
+					continue
+				}
+				assert.Equal(t, types.Success, errCode)
+				taskIDs = append(taskIDs, proverTask.TaskID)
+			}
+			assert.Equal(t, exceptTaskIDs, taskIDs)
+		})
+	}
+}
+
 func testValidProof(t *testing.T) {
 	coordinatorURL := randomURL()
 	collector, httpHandler := setupCoordinator(t, 3, coordinatorURL, map[string]int64{"istanbul": forkNumberTwo})
@@ -580,6 +681,10 @@ func testValidProof(t *testing.T) {
 		assert.Equal(t, errCode, types.Success)
 		assert.Equal(t, errMsg, "")
 		assert.NotNil(t, proverTask)
+		if proofType == message.ProofTypeBatch {
+			// submit without the uuid, as the provers that haven't upgraded yet do
+			proverTask.UUID = ""
+		}
 		provers[i].submitProof(t, proverTask, proofStatus, types.Success, "istanbul")
 	}
 
@@ -626,6 +731,119 @@ func testValidProof(t *testing.T) {
 	}
 }
 
+func testVerifyOnlyProof(t *testing.T) {
+	coordinatorURL := randomURL()
+	collector, httpHandler := setupCoordinator(t, 3, coordinatorURL, map[string]int64{"istanbul": forkNumberTwo})
+	defer func() {
+		collector.Stop()
+		assert.NoError(t, httpHandler.Shutdown(context.Background()))
+	}()
+	conf.ProverManager.VerifyOnly = true
+
+	err := l2BlockOrm.InsertL2Blocks(context.Background(), []*encoding.Block{block1, block2})
+	assert.NoError(t, err)
+	dbChunk, err := chunkOrm.InsertChunk(context.Background(), chunk)
+	assert.NoError(t, err)
+	err = l2BlockOrm.UpdateChunkHashInRange(context.Background(), 0, 100, dbChunk.Hash)
+	assert.NoError(t, err)
+	batch, err := batchOrm.InsertBatch(context.Background(), batch)
+	assert.NoError(t, err)
+	err = chunkOrm.UpdateBatchHashInRange(context.Background(), 0, 0, batch.Hash)
+	assert.NoError(t, err)
+	err = batchOrm.UpdateChunkProofsStatusByBatchHash(context.Background(), batch.Hash, types.ChunkProofsStatusReady)
+	assert.NoError(t, err)
+
+	sessionAttempts := int(conf.ProverManager.SessionAttempts)
+	for i, proofType := range []message.ProofType{message.ProofTypeChunk, message.ProofTypeBatch} {
+		// the same task is handed out again until its attempts are used up, then the provers move on
+		for j := 0; j <= sessionAttempts; j++ {
+			prover := newMockProver(t, fmt.Sprintf("prover_test_%d_%d", i, j), coordinatorURL, proofType, version.Version)
+			proverTask, errCode, errMsg := prover.getProverTask(t, proofType, "istanbul")
+			if j == sessionAttempts {
+				assert.Equal(t, types.ErrCoordinatorEmptyProofData, errCode)
+				assert.Equal(t, "get empty prover task", errMsg)
+				break
+			}
+			assert.Equal(t, types.Success, errCode)
+			assert.Equal(t, "", errMsg)
+			assert.NotNil(t, proverTask)
+			prover.submitProof(t, proverTask, verifiedSuccess, types.Success, "istanbul")
+
+			proverTaskProvingStatus, err := proverTaskOrm.GetProvingStatusByTaskID(context.Background(), proofType, proverTask.TaskID)
+			assert.NoError(t, err)
+			assert.Equal(t, types.ProverProofValid, proverTaskProvingStatus)
+		}
+	}
+
+	// the proofs are verified but not stored, and the chunk/batch end failed with all attempts used
+	dbChunk, err = chunkOrm.GetChunkByHash(context.Background(), dbChunk.Hash)
+	assert.NoError(t, err)
+	assert.Equal(t, types.ProvingTaskFailed, types.ProvingStatus(dbChunk.ProvingStatus))
+	assert.Empty(t, dbChunk.Proof)
+	assert.Equal(t, 0, int(dbChunk.ActiveAttempts))
+	assert.Equal(t, sessionAttempts, int(dbChunk.TotalAttempts))
+
+	batchProofStatus, err := batchOrm.GetProvingStatusByHash(context.Background(), batch.Hash)
+	assert.NoError(t, err)
+	assert.Equal(t, types.ProvingTaskFailed, batchProofStatus)
+	batchActiveAttempts, batchTotalAttempts, err := batchOrm.GetAttemptsByHash(context.Background(), batch.Hash)
+	assert.NoError(t, err)
+	assert.Equal(t, 0, int(batchActiveAttempts))
+	assert.Equal(t, sessionAttempts, int(batchTotalAttempts))
+}
+
+func testSuspiciousProof(t *testing.T) {
+	coordinatorURL := randomURL()
+	collector, httpHandler := setupCoordinator(t, 3, coordinatorURL, map[string]int64{"istanbul": forkNumberTwo})
+	defer func() {
+		collector.Stop()
+		assert.NoError(t, httpHandler.Shutdown(context.Background()))
+	}()
+	// every proof of the test is submitted right after its assignment
+	conf.ProverManager.MinPlausibleProofTimeSec = 3600
+
+	err := l2BlockOrm.InsertL2Blocks(context.Background(), []*encoding.Block{block1, block2})
+	assert.NoError(t, err)
+	dbChunk, err := chunkOrm.InsertChunk(context.Background(), chunk)
+	assert.NoError(t, err)
+	err = l2BlockOrm.UpdateChunkHashInRange(context.Background(), 0, 100, dbChunk.Hash)
+	assert.NoError(t, err)
+	batch, err := batchOrm.InsertBatch(context.Background(), batch)
+	assert.NoError(t, err)
+	err = chunkOrm.UpdateBatchHashInRange(context.Background(), 0, 0, batch.Hash)
+	assert.NoError(t, err)
+
+	for i, proofType := range []message.ProofType{message.ProofTypeChunk, message.ProofTypeBatch} {
+		prover := newMockProver(t, "prover_test"+strconv.Itoa(i), coordinatorURL, proofType, version.Version)
+		proverTask, errCode, errMsg := prover.getProverTask(t, proofType, "istanbul")
+		assert.Equal(t, types.Success, errCode)
+		assert.Equal(t, "", errMsg)
+		assert.NotNil(t, proverTask)
+		prover.submitProof(t, proverTask, verifiedSuccess, types.Success, "istanbul")
+	}
+
+	// the suspicious proofs are counted, but still accepted and stored
+	metricFamilies, err := metricsReg.Gather()
+	assert.NoError(t, err)
+	var suspiciousProofs float64
+	for _, metricFamily := range metricFamilies {
+		if metricFamily.GetName() != "coordinator_submit_proof_suspicious_total" {
+			continue
+		}
+		for _, metric := range metricFamily.GetMetric() {
+			suspiciousProofs += metric.GetCounter().GetValue()
+		}
+	}
+	assert.Equal(t, float64(2), suspiciousProofs)
+
+	chunkProofStatus, err := chunkOrm.GetProvingStatusByHash(context.Background(), dbChunk.Hash)
+	assert.NoError(t, err)
+	assert.Equal(t, types.ProvingTaskVerified, chunkProofStatus)
+	batchProofStatus, err := batchOrm.GetProvingStatusByHash(context.Background(), batch.Hash)
+	assert.NoError(t, err)
+	assert.Equal(t, types.ProvingTaskVerified, batchProofStatus)
+}
+
 func testInvalidProof(t *testing.T) {
 	// Setup coordinator and ws server.
 	coordinatorURL := randomURL()
@@ -684,6 +902,84 @@ func testInvalidProof(t *testing.T) {
 	}
 }
 
+func testProofTaskMismatch(t *testing.T) {
+	coordinatorURL := randomURL()
+	collector, httpHandler := setupCoordinator(t, 3, coordinatorURL, map[string]int64{"istanbul": forkNumberTwo})
+	defer func() {
+		collector.Stop()
+		assert.NoError(t, httpHandler.Shutdown(context.Background()))
+	}()
+
+	// chunk1 batch1 contains block number 2, chunk2 batch2 contains block number 3
+	err := l2BlockOrm.InsertL2Blocks(context.Background(), []*encoding.Block{block1, block2})
+	assert.NoError(t, err)
+	dbChunk1, err := chunkOrm.InsertChunk(context.Background(), hardForkChunk1)
+	assert.NoError(t, err)
+	err = l2BlockOrm.UpdateChunkHashInRange(context.Background(), 0, 2, dbChunk1.Hash)
+	assert.NoError(t, err)
+	dbBatch1, err := batchOrm.InsertBatch(context.Background(), hardForkBatch1)
+	assert.NoError(t, err)
+	err = chunkOrm.UpdateBatchHashInRange(context.Background(), 0, 0, dbBatch1.Hash)
+	assert.NoError(t, err)
+	err = batchOrm.UpdateChunkProofsStatusByBatchHash(context.Background(), dbBatch1.Hash, types.ChunkProofsStatusReady)
+	assert.NoError(t, err)
+	dbChunk2, err := chunkOrm.InsertChunk(context.Background(), hardForkChunk2)
+	assert.NoError(t, err)
+	err = l2BlockOrm.UpdateChunkHashInRange(context.Background(), 3, 100, dbChunk2.Hash)
+	assert.NoError(t, err)
+
+	// submit a proof of chunk2 with the uuid of the prover task of chunk1
+	chunkProver := newMockProver(t, "prover_test0", coordinatorURL, message.ProofTypeChunk, version.Version)
+	chunkTask, errCode, errMsg := chunkProver.getProverTask(t, message.ProofTypeChunk, "istanbul")
+	assert.Equal(t, types.Success, errCode)
+	assert.Equal(t, "", errMsg)
+	assert.Equal(t, dbChunk1.Hash, chunkTask.TaskID)
+	mismatchTask := *chunkTask
+	mismatchTask.TaskID = dbChunk2.Hash
+	chunkProver.submitProof(t, &mismatchTask, verifiedSuccess, types.ErrCoordinatorHandleZkProofFailure, "istanbul")
+
+	// submit a chunk proof with the uuid of the prover task of batch1
+	batchProver := newMockProver(t, "prover_test1", coordinatorURL, message.ProofTypeBatch, version.Version)
+	batchTask, errCode, errMsg := batchProver.getProverTask(t, message.ProofTypeBatch, "istanbul")
+	assert.Equal(t, types.Success, errCode)
+	assert.Equal(t, "", errMsg)
+	assert.Equal(t, dbBatch1.Hash, batchTask.TaskID)
+	mismatchTask = *batchTask
+	mismatchTask.TaskType = int(message.ProofTypeChunk)
+	batchProver.submitProof(t, &mismatchTask, verifiedSuccess, types.ErrCoordinatorHandleZkProofFailure, "istanbul")
+
+	// the other chunk is left untouched
+	dbChunk2, err = chunkOrm.GetChunkByHash(context.Background(), dbChunk2.Hash)
+	assert.NoError(t, err)
+	assert.Equal(t, types.ProvingTaskUnassigned, types.ProvingStatus(dbChunk2.ProvingStatus))
+	assert.Empty(t, dbChunk2.Proof)
+	assert.Equal(t, 0, int(dbChunk2.TotalAttempts))
+
+	// the assigned chunk/batch are not verified, and their prover tasks are rejected
+	dbChunk1, err = chunkOrm.GetChunkByHash(context.Background(), dbChunk1.Hash)
+	assert.NoError(t, err)
+	assert.NotEqual(t, types.ProvingTaskVerified, types.ProvingStatus(dbChunk1.ProvingStatus))
+	assert.Empty(t, dbChunk1.Proof)
+	assert.Equal(t, 0, int(dbChunk1.ActiveAttempts))
+
+	batchProofStatus, err := batchOrm.GetProvingStatusByHash(context.Background(), dbBatch1.Hash)
+	assert.NoError(t, err)
+	assert.NotEqual(t, types.ProvingTaskVerified, batchProofStatus)
+	batchActiveAttempts, _, err := batchOrm.GetAttemptsByHash(context.Background(), dbBatch1.Hash)
+	assert.NoError(t, err)
+	assert.Equal(t, 0, int(batchActiveAttempts))
+
+	for _, task := range []struct {
+		uuid      string
+		publicKey string
+	}{{chunkTask.UUID, chunkProver.publicKey()}, {batchTask.UUID, batchProver.publicKey()}} {
+		proverTask, err := proverTaskOrm.GetProverTaskByUUIDAndPublicKey(context.Background(), task.uuid, task.publicKey)
+		assert.NoError(t, err)
+		assert.Equal(t, types.ProverProofInvalid, types.ProverProveStatus(proverTask.ProvingStatus))
+		assert.Equal(t, types.ProverTaskFailureTypeVerifiedFailed, types.ProverTaskFailureType(proverTask.FailureType))
+	}
+}
+
 func testProofGeneratedFailed(t *testing.T) {
 	// Setup coordinator and ws server.
 	coordinatorURL := randomURL()
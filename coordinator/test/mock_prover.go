@@ -1,6 +1,7 @@
 package test
 
 import (
+	"bytes"
 	"crypto/ecdsa"
 	"encoding/json"
 	"fmt"
@@ -212,8 +213,8 @@ func (r *mockProver) submitProof(t *testing.T, proverTaskSchema *types.GetTaskSc
 			ID:         proverTaskSchema.TaskID,
 			Type:       message.ProofType(proverTaskSchema.TaskType),
 			Status:     proofMsgStatus,
-			ChunkProof: &message.ChunkProof{},
-			BatchProof: &message.BatchProof{},
+			ChunkProof: &message.ChunkProof{Proof: []byte("mock chunk proof")},
+			BatchProof: &message.BatchProof{Proof: bytes.Repeat([]byte("mock batch proof"), 2)},
 		},
 	}
 
@@ -226,6 +227,7 @@ func (r *mockProver) submitProof(t *testing.T, proverTaskSchema *types.GetTaskSc
 
 	assert.NoError(t, proof.Sign(r.privKey))
 	submitProof := types.SubmitProofParameter{
+		UUID:     proverTaskSchema.UUID,
 		TaskID:   proof.ID,
 		TaskType: int(proof.Type),
 		Status:   int(proof.Status),
@@ -0,0 +1,16 @@
+-- +goose Up
+-- +goose StatementBegin
+
+create index if not exists idx_chunk_start_block_number on chunk(start_block_number) where deleted_at IS NULL;
+
+create index if not exists idx_chunk_end_block_number on chunk(end_block_number) where deleted_at IS NULL;
+
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+
+drop index if exists idx_chunk_start_block_number;
+drop index if exists idx_chunk_end_block_number;
+
+-- +goose StatementEnd